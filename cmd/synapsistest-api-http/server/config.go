@@ -1,6 +1,11 @@
 package server
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
 
 func BaseConfig() string {
 	return "" +
@@ -11,3 +16,51 @@ func BaseConfig() string {
 		" port=" + os.Getenv("PGPORT") +
 		" sslmode=disable"
 }
+
+// getEnvDuration returns duration value of the given
+// environment variable, or zero if it is not set.
+func getEnvDuration(key string) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", key, err.Error())
+	}
+
+	return duration, nil
+}
+
+// getEnvInt returns int value of the given environment
+// variable, or zero if it is not set.
+func getEnvInt(key string) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", key, err.Error())
+	}
+
+	return result, nil
+}
+
+// getEnvBool returns bool value of the given environment
+// variable, or false if it is not set.
+func getEnvBool(key string) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return false, nil
+	}
+
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %s", key, err.Error())
+	}
+
+	return result, nil
+}
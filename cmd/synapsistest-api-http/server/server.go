@@ -1,21 +1,33 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/mux"
 	"github.com/jmoiron/sqlx"
+	"github.com/synapsis-test/global/cache"
+	memorycache "github.com/synapsis-test/global/cache/memory"
+	noopcache "github.com/synapsis-test/global/cache/noop"
+	rediscache "github.com/synapsis-test/global/cache/redis"
+	"github.com/synapsis-test/internal/auth"
 	"github.com/synapsis-test/internal/category"
 	categoryhttphandler "github.com/synapsis-test/internal/category/handler/http"
 	categoryservice "github.com/synapsis-test/internal/category/service"
 	categorypgstore "github.com/synapsis-test/internal/category/store/postgresql"
 	"github.com/synapsis-test/internal/order"
+	ordergateway "github.com/synapsis-test/internal/order/gateway"
+	orderfakegateway "github.com/synapsis-test/internal/order/gateway/fake"
+	ordermidtransgateway "github.com/synapsis-test/internal/order/gateway/midtrans"
 	orderhttphandler "github.com/synapsis-test/internal/order/handler/http"
+	orderreconciler "github.com/synapsis-test/internal/order/reconciler"
 	orderservice "github.com/synapsis-test/internal/order/service"
 	orderpgstore "github.com/synapsis-test/internal/order/store/postgresql"
 	"github.com/synapsis-test/internal/product"
@@ -24,6 +36,7 @@ import (
 	productpgstore "github.com/synapsis-test/internal/product/store/postgresql"
 	"github.com/synapsis-test/internal/user"
 	userhttphandler "github.com/synapsis-test/internal/user/handler/http"
+	userfilemailer "github.com/synapsis-test/internal/user/mailer/file"
 	userservice "github.com/synapsis-test/internal/user/service"
 	userpgstore "github.com/synapsis-test/internal/user/store/postgresql"
 )
@@ -36,6 +49,21 @@ const (
 	CodeFailServeHTTP
 )
 
+// paymentGatewayFake is the PAYMENT_GATEWAY config value to
+// use fake payment gateway instead of Midtrans.
+const paymentGatewayFake = "fake"
+
+// Followings are the CACHE_DRIVER config values to use other
+// cache than Redis.
+const (
+	cacheDriverMemory = "memory"
+	cacheDriverNone   = "none"
+)
+
+// defaultCacheMemoryCapacity is the number of keys kept by
+// memory cache if CACHE_MEMORY_CAPACITY is not set.
+const defaultCacheMemoryCapacity = 10000
+
 // Run creates a server and starts the server.
 //
 // Run returns a status code suitable for os.Exit() argument.
@@ -50,14 +78,24 @@ func Run() int {
 
 // server is the long-runnning application.
 type server struct {
-	srv      *http.Server
-	handlers []handler
+	srv           *http.Server
+	authenticator *auth.Authenticator
+	handlers      []handler
+	workers       []worker
 }
 
 // handler provides mechanism to start HTTP handler. All HTTP
 // handlers must implements this interface.
 type handler interface {
-	Start(multiplexer *mux.Router) error
+	Start(multiplexer *mux.Router, authenticate mux.MiddlewareFunc) error
+}
+
+// worker provides mechanism to start and stop background
+// process. All background workers must implements this
+// interface.
+type worker interface {
+	Start()
+	Stop()
 }
 
 // new creates and returns a new server.
@@ -83,6 +121,54 @@ func new() (*server, error) {
 		DB:       0,
 	})
 
+	// redis stores order idempotency keys, the token denylist
+	// and login failures, so it is required whatever the cache
+	// driver is
+	pingCtx, pingCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer pingCancel()
+	err = rdb.Ping(pingCtx).Err()
+	if err != nil {
+		log.Printf("[synapsistest-api-http] failed to connect redis: %s\n", err.Error())
+		return nil, fmt.Errorf("failed to connect redis, it is required even if CACHE_DRIVER is %s or %s: %s", cacheDriverMemory, cacheDriverNone, err.Error())
+	}
+
+	// use memory or no cache to keep cached data out of redis
+	var appCache cache.Cache
+	switch os.Getenv("CACHE_DRIVER") {
+	case cacheDriverMemory:
+		capacity, err := getEnvInt("CACHE_MEMORY_CAPACITY")
+		if err != nil {
+			log.Printf("[synapsistest-api-http] failed to read cache config: %s\n", err.Error())
+			return nil, fmt.Errorf("failed to read cache config: %s", err.Error())
+		}
+		if capacity == 0 {
+			capacity = defaultCacheMemoryCapacity
+		}
+
+		appCache, err = memorycache.New(capacity)
+		if err != nil {
+			log.Printf("[synapsistest-api-http] failed to initialize memory cache: %s\n", err.Error())
+			return nil, fmt.Errorf("failed to initialize memory cache: %s", err.Error())
+		}
+	case cacheDriverNone:
+		appCache = noopcache.New()
+	default:
+		appCache = rediscache.New(rdb)
+	}
+
+	// read whether users need verified email to login and order
+	requireVerifiedEmail, err := getEnvBool("REQUIRE_VERIFIED_EMAIL")
+	if err != nil {
+		log.Printf("[synapsistest-api-http] failed to read verified email config: %s\n", err.Error())
+		return nil, fmt.Errorf("failed to read verified email config: %s", err.Error())
+	}
+
+	bcryptCost, err := getEnvInt("BCRYPT_COST")
+	if err != nil {
+		log.Printf("[synapsistest-api-http] failed to read password hash config: %s\n", err.Error())
+		return nil, fmt.Errorf("failed to read password hash config: %s", err.Error())
+	}
+
 	// initialize user service
 	var userSvc user.Service
 	{
@@ -94,30 +180,42 @@ func new() (*server, error) {
 
 		svcOptions := []userservice.Option{}
 		svcOptions = append(svcOptions, userservice.WithConfig(userservice.Config{
-			PasswordSalt:   os.Getenv("PasswordSalt"),
-			TokenSecretKey: os.Getenv("TokenSecretKey"),
+			PasswordSalt:          os.Getenv("PasswordSalt"),
+			TokenSecretKey:        os.Getenv("TokenSecretKey"),
+			RequireVerifiedEmail:  requireVerifiedEmail,
+			PasswordHashAlgorithm: os.Getenv("PASSWORD_HASH_ALGORITHM"),
+			BcryptCost:            bcryptCost,
 		}))
 
-		userSvc, err = userservice.New(pgStore, svcOptions...)
+		// mails are written to a file or the log until a mail
+		// provider is integrated
+		mailer, err := userfilemailer.New(os.Getenv("MAILER_FILE"))
 		if err != nil {
-			log.Printf("[user-api-http] failed to initialize user service: %s\n", err.Error())
-			return nil, fmt.Errorf("failed to initialize user service: %s", err.Error())
+			log.Printf("[user-api-http] failed to initialize file mailer: %s\n", err.Error())
+			return nil, fmt.Errorf("failed to initialize file mailer: %s", err.Error())
 		}
-	}
 
-	// initialize product service
-	var productSvc product.Service
-	{
-		pgStore, err := productpgstore.New(db)
+		userSvc, err = userservice.New(pgStore, rdb, mailer, svcOptions...)
 		if err != nil {
-			log.Printf("[product-api-http] failed to initialize product postgresql store: %s\n", err.Error())
-			return nil, fmt.Errorf("failed to initialize product postgresql store: %s", err.Error())
+			log.Printf("[user-api-http] failed to initialize user service: %s\n", err.Error())
+			return nil, fmt.Errorf("failed to initialize user service: %s", err.Error())
 		}
 
-		productSvc, err = productservice.New(pgStore, rdb)
-		if err != nil {
-			log.Printf("[product-api-http] failed to initialize product service: %s\n", err.Error())
-			return nil, fmt.Errorf("failed to initialize product service: %s", err.Error())
+		// bootstrap admin account if configured
+		if adminEmail := os.Getenv("ADMIN_EMAIL"); adminEmail != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			adminID, err := userSvc.EnsureAdmin(ctx, user.User{
+				Email:       adminEmail,
+				Name:        os.Getenv("ADMIN_NAME"),
+				Password:    os.Getenv("ADMIN_PASSWORD"),
+				PhoneNumber: os.Getenv("ADMIN_PHONE_NUMBER"),
+			})
+			cancel()
+			if err != nil {
+				log.Printf("[user-api-http] failed to bootstrap admin: %s\n", err.Error())
+				return nil, fmt.Errorf("failed to bootstrap admin: %s", err.Error())
+			}
+			log.Printf("[user-api-http] admin is ready. userID: %d\n", adminID)
 		}
 	}
 
@@ -130,13 +228,29 @@ func new() (*server, error) {
 			return nil, fmt.Errorf("failed to initialize category postgresql store: %s", err.Error())
 		}
 
-		categorySvc, err = categoryservice.New(pgStore)
+		categorySvc, err = categoryservice.New(pgStore, appCache)
 		if err != nil {
 			log.Printf("[category-api-http] failed to initialize category service: %s\n", err.Error())
 			return nil, fmt.Errorf("failed to initialize category service: %s", err.Error())
 		}
 	}
 
+	// initialize product service
+	var productSvc product.Service
+	{
+		pgStore, err := productpgstore.New(db)
+		if err != nil {
+			log.Printf("[product-api-http] failed to initialize product postgresql store: %s\n", err.Error())
+			return nil, fmt.Errorf("failed to initialize product postgresql store: %s", err.Error())
+		}
+
+		productSvc, err = productservice.New(pgStore, categorySvc, appCache)
+		if err != nil {
+			log.Printf("[product-api-http] failed to initialize product service: %s\n", err.Error())
+			return nil, fmt.Errorf("failed to initialize product service: %s", err.Error())
+		}
+	}
+
 	// initialize order service
 	var orderSvc order.Service
 	{
@@ -146,13 +260,77 @@ func new() (*server, error) {
 			return nil, fmt.Errorf("failed to initialize order postgresql store: %s", err.Error())
 		}
 
-		orderSvc, err = orderservice.New(pgStore, productSvc)
+		// fake payment gateway accepts a well-known signature,
+		// so it is only used if explicitly set for local
+		// development. Otherwise Midtrans is used, which refuses
+		// to start without its server key.
+		var paymentGateway ordergateway.PaymentGateway
+		switch os.Getenv("PAYMENT_GATEWAY") {
+		case paymentGatewayFake:
+			log.Println("[order-api-http] using fake payment gateway, do not use it in production")
+			paymentGateway = orderfakegateway.New()
+		default:
+			paymentGateway, err = ordermidtransgateway.New(ordermidtransgateway.Config{
+				ServerKey:   os.Getenv("MIDTRANS_SERVER_KEY"),
+				Environment: os.Getenv("MIDTRANS_ENVIRONMENT"),
+			})
+			if err != nil {
+				log.Printf("[order-api-http] failed to initialize midtrans payment gateway: %s\n", err.Error())
+				return nil, fmt.Errorf("failed to initialize midtrans payment gateway: %s", err.Error())
+			}
+		}
+
+		orderSvc, err = orderservice.New(pgStore, productSvc, paymentGateway, rdb)
 		if err != nil {
 			log.Printf("[order-api-http] failed to initialize order service: %s\n", err.Error())
 			return nil, fmt.Errorf("failed to initialize order service: %s", err.Error())
 		}
 	}
 
+	// initialize order reconciler
+	{
+		interval, err := getEnvDuration("RECONCILER_INTERVAL")
+		if err != nil {
+			log.Printf("[order-api-http] failed to read order reconciler config: %s\n", err.Error())
+			return nil, fmt.Errorf("failed to read order reconciler config: %s", err.Error())
+		}
+
+		batchSize, err := getEnvInt("RECONCILER_BATCH_SIZE")
+		if err != nil {
+			log.Printf("[order-api-http] failed to read order reconciler config: %s\n", err.Error())
+			return nil, fmt.Errorf("failed to read order reconciler config: %s", err.Error())
+		}
+
+		pendingAge, err := getEnvDuration("RECONCILER_PENDING_AGE")
+		if err != nil {
+			log.Printf("[order-api-http] failed to read order reconciler config: %s\n", err.Error())
+			return nil, fmt.Errorf("failed to read order reconciler config: %s", err.Error())
+		}
+
+		reconciler, err := orderreconciler.New(orderSvc, orderreconciler.WithConfig(orderreconciler.Config{
+			Interval:   interval,
+			BatchSize:  batchSize,
+			PendingAge: pendingAge,
+		}))
+		if err != nil {
+			log.Printf("[order-api-http] failed to initialize order reconciler: %s\n", err.Error())
+			return nil, fmt.Errorf("failed to initialize order reconciler: %s", err.Error())
+		}
+
+		s.workers = append(s.workers, reconciler)
+	}
+
+	// initialize authenticator shared by HTTP handlers
+	{
+		authenticator, err := auth.New(userSvc)
+		if err != nil {
+			log.Printf("[synapsistest-api-http] failed to initialize authenticator: %s\n", err.Error())
+			return nil, fmt.Errorf("failed to initialize authenticator: %s", err.Error())
+		}
+
+		s.authenticator = authenticator
+	}
+
 	// initialize user HTTP handler
 	{
 		identities := []userhttphandler.HandlerIdentity{
@@ -160,6 +338,14 @@ func new() (*server, error) {
 			userhttphandler.HandlerToken,
 			userhttphandler.HandlerLogin,
 			userhttphandler.HandlerUsers,
+			userhttphandler.HandlerMyPassword,
+			userhttphandler.HandlerProfile,
+			userhttphandler.HandlerMyProfile,
+			userhttphandler.HandlerLogout,
+			userhttphandler.HandlerPasswordForgot,
+			userhttphandler.HandlerPasswordReset,
+			userhttphandler.HandlerEmailVerification,
+			userhttphandler.HandlerEmailVerificationResend,
 		}
 
 		userHTTP, err := userhttphandler.New(userSvc, identities)
@@ -177,10 +363,12 @@ func new() (*server, error) {
 			producthttphandler.HandlerProductsCart,
 			producthttphandler.HandlerProduct,
 			producthttphandler.HandlerProducts,
+			producthttphandler.HandlerProductsSuggest,
 			producthttphandler.HandlerProductCart,
+			producthttphandler.HandlerMyCarts,
 		}
 
-		productHTTP, err := producthttphandler.New(productSvc, userSvc, identities)
+		productHTTP, err := producthttphandler.New(productSvc, identities)
 		if err != nil {
 			log.Printf("[product-api-http] failed to initialize product http handlers: %s\n", err.Error())
 			return nil, fmt.Errorf("failed to initialize product http handlers: %s", err.Error())
@@ -196,7 +384,7 @@ func new() (*server, error) {
 			categoryhttphandler.HandlerCategories,
 		}
 
-		categoryHTTP, err := categoryhttphandler.New(categorySvc, userSvc, identities)
+		categoryHTTP, err := categoryhttphandler.New(categorySvc, identities)
 		if err != nil {
 			log.Printf("[category-api-http] failed to initialize category http handlers: %s\n", err.Error())
 			return nil, fmt.Errorf("failed to initialize category http handlers: %s", err.Error())
@@ -210,9 +398,18 @@ func new() (*server, error) {
 		identities := []orderhttphandler.HandlerIdentity{
 			orderhttphandler.HandlerOrder,
 			orderhttphandler.HandlerOrders,
+			orderhttphandler.HandlerOrderCancel,
+			orderhttphandler.HandlerOrderRefund,
+			orderhttphandler.HandlerOrderHistory,
+			orderhttphandler.HandlerPaymentNotification,
 		}
 
-		orderHTTP, err := orderhttphandler.New(orderSvc, userSvc, identities)
+		options := []orderhttphandler.Option{}
+		if requireVerifiedEmail {
+			options = append(options, orderhttphandler.WithVerifiedEmailRequired(userSvc))
+		}
+
+		orderHTTP, err := orderhttphandler.New(orderSvc, identities, options...)
 		if err != nil {
 			log.Printf("[order-api-http] failed to initialize order http handlers: %s\n", err.Error())
 			return nil, fmt.Errorf("failed to initialize order http handlers: %s", err.Error())
@@ -234,7 +431,7 @@ func (s *server) start() int {
 
 	// starts handlers
 	for _, h := range s.handlers {
-		if err := h.Start(appMux); err != nil {
+		if err := h.Start(appMux, s.authenticator.Authenticate); err != nil {
 			log.Printf("[synapsis-test-api-http] failed to start handler: %s\n", err.Error())
 			return CodeFailServeHTTP
 		}
@@ -248,11 +445,48 @@ func (s *server) start() int {
 	// use middlewares to app mux only
 	appMux.Use(corsMiddleware)
 
+	// starts background workers
+	for _, w := range s.workers {
+		w.Start()
+	}
+
 	// listen and serve
-	log.Printf("[synapsis-test-api-http] Server is running at %s:%s", os.Getenv("ADDRESS"), os.Getenv("PORT"))
-	log.Fatal(http.ListenAndServe(fmt.Sprintf("%s:%s", os.Getenv("ADDRESS"), os.Getenv("PORT")), rootMux))
+	s.srv.Addr = fmt.Sprintf("%s:%s", os.Getenv("ADDRESS"), os.Getenv("PORT"))
+	s.srv.Handler = rootMux
+
+	errChan := make(chan error, 1)
+	go func() {
+		log.Printf("[synapsis-test-api-http] Server is running at %s", s.srv.Addr)
+		errChan <- s.srv.ListenAndServe()
+	}()
+
+	// wait until server is failed or terminated
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	code := CodeSuccess
+	select {
+	case err := <-errChan:
+		log.Printf("[synapsis-test-api-http] failed to serve HTTP: %s\n", err.Error())
+		code = CodeFailServeHTTP
+	case sig := <-sigChan:
+		log.Printf("[synapsis-test-api-http] received %s, shutting down server...\n", sig)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := s.srv.Shutdown(ctx); err != nil {
+			log.Printf("[synapsis-test-api-http] failed to shutdown server: %s\n", err.Error())
+			code = CodeFailServeHTTP
+		}
+	}
+
+	// stops background workers
+	for _, w := range s.workers {
+		w.Stop()
+	}
 
-	return CodeSuccess
+	return code
 }
 
 func corsMiddleware(h http.Handler) http.Handler {
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Followings are the known errors returned from cache.
+var (
+	// ErrNotFound is returned when the desired key is not
+	// cached or already expired.
+	ErrNotFound = errors.New("cache: key not found")
+)
+
+// Cache stores values by key for a limited time.
+type Cache interface {
+	// Get returns the value of the given key.
+	//
+	// It returns ErrNotFound if the key is not cached.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores the given value with the given key for the
+	// given TTL. The value never expires if the TTL is not
+	// positive.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the given keys.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// GetJSON reads the value of the given key into the given
+// destination as JSON.
+//
+// It returns ErrNotFound if the key is not cached.
+func GetJSON(ctx context.Context, c Cache, key string, dst interface{}) error {
+	value, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(value, dst)
+}
+
+// SetJSON stores the given value with the given key as JSON
+// for the given TTL.
+func SetJSON(ctx context.Context, c Cache, key string, value interface{}, ttl time.Duration) error {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return c.Set(ctx, key, valueJSON, ttl)
+}
+
+// GetVersion returns the version stored in the given key, or
+// "0" if there is none yet. Versions are put in other keys,
+// so changing the version invalidates all of them at once.
+func GetVersion(ctx context.Context, c Cache, key string) (string, error) {
+	version, err := c.Get(ctx, key)
+	if err == ErrNotFound {
+		return "0", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return string(version), nil
+}
+
+// BumpVersion replaces the version stored in the given key
+// with a new one derived from the given time.
+func BumpVersion(ctx context.Context, c Cache, key string, now time.Time) error {
+	return c.Set(ctx, key, []byte(strconv.FormatInt(now.UnixNano(), 36)), 0)
+}
+
+// bypassKey is the context key to bypass caches.
+type bypassKey struct{}
+
+// WithBypass returns a copy of the given context that makes
+// read-through caches read from the source, for the reads
+// that are about to be written back.
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// IsBypassed returns whether the given context bypasses
+// caches.
+func IsBypassed(ctx context.Context) bool {
+	bypassed, _ := ctx.Value(bypassKey{}).(bool)
+	return bypassed
+}
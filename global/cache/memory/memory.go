@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/synapsis-test/global/cache"
+)
+
+// Followings are the known error returned from memory cache.
+var (
+	errInvalidCapacity = errors.New("capacity must be positive")
+)
+
+// client implements cache.Cache in process memory. It keeps
+// at most the given number of keys with TTL, and evicts the
+// least recently used one to store a new one. Keys without
+// TTL, such as versions, are kept apart and never evicted,
+// as losing them would bring back the values cached in their
+// previous versions.
+//
+// Values are not shared across processes, so it is suitable
+// for a single instance or local development.
+type client struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	lru      *list.List        // front is the most recently used
+	persists map[string][]byte // keys without TTL
+	timeNow  func() time.Time
+}
+
+// entry denotes a cached value with TTL.
+type entry struct {
+	key      string
+	value    []byte
+	expireAt time.Time
+}
+
+// New creates a new memory cache that keeps at most the
+// given number of keys.
+func New(capacity int) (*client, error) {
+	if capacity <= 0 {
+		return nil, errInvalidCapacity
+	}
+
+	return &client{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		persists: make(map[string][]byte),
+		timeNow:  time.Now,
+	}, nil
+}
+
+func (c *client) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored, ok := c.persists[key]
+	if !ok {
+		elem, ok := c.entries[key]
+		if !ok {
+			return nil, cache.ErrNotFound
+		}
+
+		e := elem.Value.(*entry)
+		if c.isExpired(e) {
+			c.remove(elem)
+			return nil, cache.ErrNotFound
+		}
+
+		c.lru.MoveToFront(elem)
+		stored = e.value
+	}
+
+	// copy so the cached value can not be modified
+	value := make([]byte, len(stored))
+	copy(value, stored)
+
+	return value, nil
+}
+
+func (c *client) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	// copy so the cached value can not be modified
+	stored := make([]byte, len(value))
+	copy(stored, value)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// keep the key without TTL apart from the evicted ones
+	if ttl <= 0 {
+		if elem, ok := c.entries[key]; ok {
+			c.remove(elem)
+		}
+		c.persists[key] = stored
+		return nil
+	}
+	delete(c.persists, key)
+
+	expireAt := c.timeNow().Add(ttl)
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*entry)
+		e.value = stored
+		e.expireAt = expireAt
+		c.lru.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.lru.PushFront(&entry{
+		key:      key,
+		value:    stored,
+		expireAt: expireAt,
+	})
+
+	// evict the least recently used keys
+	for c.lru.Len() > c.capacity {
+		c.remove(c.lru.Back())
+	}
+
+	return nil
+}
+
+func (c *client) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.entries[key]; ok {
+			c.remove(elem)
+		}
+		delete(c.persists, key)
+	}
+
+	return nil
+}
+
+// isExpired returns whether the given entry is expired.
+func (c *client) isExpired(e *entry) bool {
+	return !c.timeNow().Before(e.expireAt)
+}
+
+// remove removes the given element from the cache. The caller
+// must hold the lock.
+func (c *client) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*entry).key)
+}
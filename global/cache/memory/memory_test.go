@@ -0,0 +1,200 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/synapsis-test/global/cache"
+)
+
+// newTestClient returns a memory cache with the given
+// capacity, whose time is controlled by the returned function.
+func newTestClient(t *testing.T, capacity int) (*client, func(d time.Duration)) {
+	t.Helper()
+
+	c, err := New(capacity)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.timeNow = func() time.Time { return now }
+
+	return c, func(d time.Duration) { now = now.Add(d) }
+}
+
+// assertValue asserts that the given key is cached with the
+// given value.
+func assertValue(t *testing.T, c *client, key string, want string) {
+	t.Helper()
+
+	got, err := c.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%s) error = %v, want %s", key, err, want)
+	}
+	if string(got) != want {
+		t.Errorf("Get(%s) = %s, want %s", key, got, want)
+	}
+}
+
+// assertNotFound asserts that the given key is not cached.
+func assertNotFound(t *testing.T, c *client, key string) {
+	t.Helper()
+
+	_, err := c.Get(context.Background(), key)
+	if err != cache.ErrNotFound {
+		t.Errorf("Get(%s) error = %v, want %v", key, err, cache.ErrNotFound)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, capacity := range []int{0, -1} {
+		_, err := New(capacity)
+		if err != errInvalidCapacity {
+			t.Errorf("New(%d) error = %v, want %v", capacity, err, errInvalidCapacity)
+		}
+	}
+}
+
+func TestClientSetGet(t *testing.T) {
+	c, _ := newTestClient(t, 10)
+	ctx := context.Background()
+
+	assertNotFound(t, c, "a")
+
+	if err := c.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	assertValue(t, c, "a", "1")
+
+	// overwrite
+	if err := c.Set(ctx, "a", []byte("2"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	assertValue(t, c, "a", "2")
+}
+
+func TestClientCopiesValue(t *testing.T) {
+	c, _ := newTestClient(t, 10)
+	ctx := context.Background()
+
+	value := []byte("abc")
+	if err := c.Set(ctx, "a", value, 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	// modifying the given value does not modify the cache
+	value[0] = 'x'
+	assertValue(t, c, "a", "abc")
+
+	// modifying the returned value does not modify the cache
+	got, err := c.Get(ctx, "a")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got[0] = 'y'
+	assertValue(t, c, "a", "abc")
+}
+
+func TestClientTTL(t *testing.T) {
+	c, advance := newTestClient(t, 10)
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "short", []byte("1"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := c.Set(ctx, "forever", []byte("2"), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	advance(time.Minute - time.Second)
+	assertValue(t, c, "short", "1")
+
+	advance(time.Second)
+	assertNotFound(t, c, "short")
+	assertValue(t, c, "forever", "2")
+
+	// expired key is removed, so it does not take capacity
+	if _, ok := c.entries["short"]; ok {
+		t.Errorf("expired key is still stored")
+	}
+}
+
+func TestClientLRU(t *testing.T) {
+	c, _ := newTestClient(t, 2)
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := c.Set(ctx, "b", []byte("2"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	// reading "a" makes "b" the least recently used
+	assertValue(t, c, "a", "1")
+
+	if err := c.Set(ctx, "c", []byte("3"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	assertNotFound(t, c, "b")
+	assertValue(t, c, "a", "1")
+	assertValue(t, c, "c", "3")
+
+	if c.lru.Len() != 2 || len(c.entries) != 2 {
+		t.Errorf("stored %d keys in list and %d in map, want 2", c.lru.Len(), len(c.entries))
+	}
+}
+
+func TestClientKeepsKeysWithoutTTL(t *testing.T) {
+	c, _ := newTestClient(t, 1)
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "version", []byte("1"), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	// the keys with TTL do not evict the key without TTL
+	for _, key := range []string{"a", "b"} {
+		if err := c.Set(ctx, key, []byte(key), time.Minute); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+
+	assertValue(t, c, "version", "1")
+	assertNotFound(t, c, "a")
+	assertValue(t, c, "b", "b")
+
+	// setting TTL moves the key back to be evicted
+	if err := c.Set(ctx, "version", []byte("2"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	assertValue(t, c, "version", "2")
+	assertNotFound(t, c, "b")
+
+	if err := c.Delete(ctx, "version"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	assertNotFound(t, c, "version")
+}
+
+func TestClientDelete(t *testing.T) {
+	c, _ := newTestClient(t, 10)
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := c.Set(ctx, key, []byte(key), 0); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+
+	if err := c.Delete(ctx, "a", "b", "unknown"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	assertNotFound(t, c, "a")
+	assertNotFound(t, c, "b")
+	assertValue(t, c, "c", "c")
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// setTimeout is the timeout to cache a loaded value.
+const setTimeout = 2 * time.Second
+
+// ReadThrough reads values through a cache, and loads them
+// from their source when they are not cached.
+type ReadThrough struct {
+	cache Cache
+	ttl   time.Duration
+
+	// loadGroup deduplicates concurrent loads of the same
+	// key.
+	loadGroup singleflight.Group
+}
+
+// NewReadThrough creates a new read-through cache that caches
+// the loaded values in the given cache for the given TTL.
+func NewReadThrough(c Cache, ttl time.Duration) *ReadThrough {
+	return &ReadThrough{
+		cache: c,
+		ttl:   ttl,
+	}
+}
+
+// Get reads the cached value of the key returned by the given
+// formatKey function into the given destination as JSON,
+// otherwise it loads the value using the given load function
+// and caches it.
+//
+// Only one load runs at a time for the same key, so expired
+// keys do not flood the source. Cache failures are logged and
+// the value is loaded from source instead. The value is
+// neither read from nor written to cache if its key can not
+// be formatted, as it may be stale, or if the given context
+// bypasses the cache.
+func (rt *ReadThrough) Get(ctx context.Context, formatKey func(ctx context.Context) (string, error), dst interface{}, load func() (interface{}, error)) error {
+	if IsBypassed(ctx) {
+		return loadInto(dst, load)
+	}
+
+	key, err := formatKey(ctx)
+	if err != nil {
+		log.Printf("[Cache][ReadThrough] Failed to format cache key. Err: %s\n", err.Error())
+		return loadInto(dst, load)
+	}
+
+	err = GetJSON(ctx, rt.cache, key, dst)
+	if err == nil {
+		return nil
+	}
+	if err != ErrNotFound {
+		log.Printf("[Cache][ReadThrough] Failed to get cache. key: %s, Err: %s\n", key, err.Error())
+	}
+
+	resChan := rt.loadGroup.DoChan(key, func() (interface{}, error) {
+		value, err := loadJSON(load)
+		if err != nil {
+			return nil, err
+		}
+
+		// the value is shared by every caller waiting for it,
+		// so it is cached even if the first caller gives up
+		setCtx, cancel := context.WithTimeout(context.Background(), setTimeout)
+		defer cancel()
+
+		err = rt.cache.Set(setCtx, key, value, rt.ttl)
+		if err != nil {
+			log.Printf("[Cache][ReadThrough] Failed to set cache. key: %s, Err: %s\n", key, err.Error())
+		}
+
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-resChan:
+		if res.Err != nil {
+			return res.Err
+		}
+		// every caller decodes its own copy of the value
+		return json.Unmarshal(res.Val.([]byte), dst)
+	}
+}
+
+// loadInto loads the value using the given load function
+// into the given destination, the same way as it is read
+// from cache.
+func loadInto(dst interface{}, load func() (interface{}, error)) error {
+	value, err := loadJSON(load)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(value, dst)
+}
+
+// loadJSON returns the value loaded by the given load
+// function as JSON.
+func loadJSON(load func() (interface{}, error)) ([]byte, error) {
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
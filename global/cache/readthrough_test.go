@@ -0,0 +1,182 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/synapsis-test/global/cache"
+	"github.com/synapsis-test/global/cache/memory"
+)
+
+// newTestReadThrough returns a read-through cache using
+// memory cache.
+func newTestReadThrough(t *testing.T) *cache.ReadThrough {
+	t.Helper()
+
+	c, err := memory.New(10)
+	if err != nil {
+		t.Fatalf("memory.New() error = %v", err)
+	}
+
+	return cache.NewReadThrough(c, time.Minute)
+}
+
+// formatTestKey returns the given key as is.
+func formatTestKey(key string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		return key, nil
+	}
+}
+
+func TestReadThroughGet(t *testing.T) {
+	rt := newTestReadThrough(t)
+	ctx := context.Background()
+
+	var loads int32
+	load := func() (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		return []string{"a", "b"}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		var got []string
+		err := rt.Get(ctx, formatTestKey("key"), &got, load)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+			t.Errorf("Get() = %v, want [a b]", got)
+		}
+	}
+
+	if loads != 1 {
+		t.Errorf("loaded %d times, want 1", loads)
+	}
+}
+
+func TestReadThroughGetBypass(t *testing.T) {
+	rt := newTestReadThrough(t)
+
+	var loads int32
+	load := func() (interface{}, error) {
+		return atomic.AddInt32(&loads, 1), nil
+	}
+
+	var got int32
+	for i := 0; i < 2; i++ {
+		err := rt.Get(cache.WithBypass(context.Background()), formatTestKey("key"), &got, load)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+
+	if got != 2 || loads != 2 {
+		t.Errorf("Get() = %d after %d loads, want 2 after 2 loads", got, loads)
+	}
+
+	// the bypassed values are not cached either
+	err := rt.Get(context.Background(), formatTestKey("key"), &got, load)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got != 3 {
+		t.Errorf("Get() = %d, want 3", got)
+	}
+}
+
+func TestReadThroughGetKeyError(t *testing.T) {
+	rt := newTestReadThrough(t)
+	ctx := context.Background()
+
+	var loads int32
+	load := func() (interface{}, error) {
+		return atomic.AddInt32(&loads, 1), nil
+	}
+	formatKey := func(ctx context.Context) (string, error) {
+		return "", errors.New("version unavailable")
+	}
+
+	var got int32
+	for i := 0; i < 2; i++ {
+		err := rt.Get(ctx, formatKey, &got, load)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+
+	// without key the value is never cached
+	if loads != 2 {
+		t.Errorf("loaded %d times, want 2", loads)
+	}
+}
+
+func TestReadThroughGetLoadError(t *testing.T) {
+	rt := newTestReadThrough(t)
+	ctx := context.Background()
+
+	errLoad := errors.New("load failed")
+	var got int
+	err := rt.Get(ctx, formatTestKey("key"), &got, func() (interface{}, error) {
+		return nil, errLoad
+	})
+	if err != errLoad {
+		t.Fatalf("Get() error = %v, want %v", err, errLoad)
+	}
+
+	// the failure is not cached
+	err = rt.Get(ctx, formatTestKey("key"), &got, func() (interface{}, error) {
+		return 1, nil
+	})
+	if err != nil || got != 1 {
+		t.Errorf("Get() = %d, %v, want 1, nil", got, err)
+	}
+}
+
+func TestReadThroughGetConcurrent(t *testing.T) {
+	rt := newTestReadThrough(t)
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	var loads int32
+	load := func() (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return 1, nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var got int
+			err := rt.Get(ctx, formatTestKey("key"), &got, load)
+			if err == nil && got != 1 {
+				err = errors.New("unexpected value")
+			}
+			errs <- err
+		}()
+	}
+
+	// let every caller wait for the first load
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Get() error = %v", err)
+		}
+	}
+
+	if loads != 1 {
+		t.Errorf("loaded %d times, want 1", loads)
+	}
+}
@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/synapsis-test/global/cache"
+)
+
+// client implements cache.Cache using Redis.
+type client struct {
+	redisClient *redis.Client
+}
+
+// New creates a new Redis cache using the given redis
+// client.
+func New(redisClient *redis.Client) *client {
+	return &client{
+		redisClient: redisClient,
+	}
+}
+
+func (c *client) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.redisClient.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, cache.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (c *client) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	// zero expiration means no expiration in redis
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return c.redisClient.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *client) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return c.redisClient.Del(ctx, keys...).Err()
+}
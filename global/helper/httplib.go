@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 )
@@ -115,3 +116,16 @@ func GetBearerTokenFromHeader(r *http.Request) (string, error) {
 
 	return parts[1], nil
 }
+
+// GetClientIP returns IP address of the client who sends the
+// HTTP request.
+//
+// Value is taken from the connection, headers set by the
+// client are not trusted.
+func GetClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,115 @@
+// Package auth provides HTTP authentication shared by every
+// HTTP handler.
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/user"
+)
+
+// validateTimeout is the timeout to validate a token.
+const validateTimeout = 1000 * time.Millisecond
+
+// Followings are the known errors from auth.
+var (
+	// ErrUnauthenticated is returned when the request is not
+	// authenticated.
+	ErrUnauthenticated = errors.New("unauthenticated")
+
+	// errInvalidToken is returned when the given token is
+	// invalid.
+	errInvalidToken = errors.New("INVALID_TOKEN")
+
+	// errExpiredToken is returned when the given token is
+	// expired.
+	errExpiredToken = errors.New("EXPIRED_TOKEN")
+
+	// errRevokedToken is returned when the given token is
+	// revoked.
+	errRevokedToken = errors.New("REVOKED_TOKEN")
+
+	// errUnauthorizedAccess is returned when the request
+	// is unaothorized.
+	errUnauthorizedAccess = errors.New("UNAUTHORIZED_ACCESS")
+
+	// errRequestTimeout is returned when processing time has
+	// reached the timeout limit.
+	errRequestTimeout = errors.New("REQUEST_TIMEOUT")
+)
+
+// Authenticator authenticates HTTP requests using bearer
+// token.
+type Authenticator struct {
+	user user.Service
+}
+
+// New creates a new Authenticator.
+func New(user user.Service) (*Authenticator, error) {
+	a := &Authenticator{
+		user: user,
+	}
+
+	return a, nil
+}
+
+// Authenticate is a mux middleware that only serves request
+// with a valid bearer token, and puts the data encapsulated
+// in the token into the request context.
+func (a *Authenticator) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// get token from header
+		token, err := helper.GetBearerTokenFromHeader(r)
+		if err != nil {
+			helper.WriteErrorResponse(w, http.StatusBadRequest, []string{errInvalidToken.Error()})
+			return
+		}
+
+		// validate token
+		ctx, cancel := context.WithTimeout(r.Context(), validateTimeout)
+		tokenData, err := a.user.ValidateToken(ctx, token)
+		cancel()
+		if err != nil {
+			switch err {
+			case user.ErrExpiredToken:
+				helper.WriteErrorResponse(w, http.StatusUnauthorized, []string{errExpiredToken.Error()})
+			case user.ErrRevokedToken:
+				helper.WriteErrorResponse(w, http.StatusUnauthorized, []string{errRevokedToken.Error()})
+			case context.DeadlineExceeded:
+				helper.WriteErrorResponse(w, http.StatusGatewayTimeout, []string{errRequestTimeout.Error()})
+			default:
+				log.Printf("[Auth][Authenticate] Unauthorized error from ValidateToken. Err: %s\n", err.Error())
+				helper.WriteErrorResponse(w, http.StatusUnauthorized, []string{errUnauthorizedAccess.Error()})
+			}
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(user.NewContext(r.Context(), tokenData)))
+	})
+}
+
+// Authorize returns the data encapsulated in the token of
+// the authenticated request carried in the given context,
+// if it satisfies all the given requirements.
+//
+// It returns ErrUnauthenticated if the request is not
+// authenticated, or user.ErrForbidden if any requirement is
+// not satisfied.
+func Authorize(ctx context.Context, requirements ...user.Requirement) (user.TokenData, error) {
+	tokenData, ok := user.TokenDataFromContext(ctx)
+	if !ok {
+		return user.TokenData{}, ErrUnauthenticated
+	}
+
+	err := user.Authorize(tokenData, requirements...)
+	if err != nil {
+		return user.TokenData{}, err
+	}
+
+	return tokenData, nil
+}
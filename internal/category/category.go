@@ -5,12 +5,42 @@ import (
 	"time"
 )
 
+// CacheVersionKey is the cache key of the version of
+// categories, which is changed on every category change so
+// caches that carry category data can be invalidated.
+const CacheVersionKey = "category:version"
+
 type Service interface {
 	// GetCategoryByID returns a category with the given category ID.
 	GetCategoryByID(ctx context.Context, id int64) (Category, error)
 
 	// GetCategories returns list of categories.
 	GetCategories(ctx context.Context) ([]Category, error)
+
+	// CreateCategory creates a new category as given, and
+	// returns the created category ID.
+	//
+	// It returns ErrCategoryAlreadyExist if there is other
+	// category with the same name.
+	CreateCategory(ctx context.Context, category Category) (int64, error)
+
+	// UpdateCategory updates the category with the ID of the
+	// given category. It updates all the fields except ID and
+	// CreateTime, so make sure to use current values for the
+	// fields that should not be updated.
+	//
+	// It returns ErrCategoryAlreadyExist if there is other
+	// category with the same name.
+	UpdateCategory(ctx context.Context, category Category) error
+
+	// DeleteCategory deletes the category with the given
+	// category ID.
+	//
+	// It returns ErrCategoryInUse if the category still has
+	// products, unless the given reassign category ID is
+	// given, in which case the products are moved to that
+	// category first.
+	DeleteCategory(ctx context.Context, id int64, reassignCategoryID int64) error
 }
 
 type Category struct {
@@ -11,4 +11,16 @@ var (
 	// ErrInvalidCategoryID is returned when the given category ID is
 	// invalid.
 	ErrInvalidCategoryID = errors.New("invalid category id")
+
+	// ErrInvalidName is returned when the given category name
+	// is invalid.
+	ErrInvalidName = errors.New("invalid name")
+
+	// ErrCategoryAlreadyExist is returned when there is
+	// already other category with the given name.
+	ErrCategoryAlreadyExist = errors.New("category already exist")
+
+	// ErrCategoryInUse is returned when deleting a category
+	// that still has products.
+	ErrCategoryInUse = errors.New("category in use")
 )
@@ -3,24 +3,27 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
 	"github.com/synapsis-test/internal/category"
 	"github.com/synapsis-test/internal/user"
 )
 
 type categoriesHandler struct {
 	category category.Service
-	client   user.Service
 }
 
 func (h *categoriesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		h.handleGetCategories(w, r)
+	case http.MethodPost:
+		h.handleCreateCategory(w, r)
 	default:
 		helper.WriteErrorResponse(w, http.StatusMethodNotAllowed, []string{errMethodNotAllowed.Error()})
 	}
@@ -54,19 +57,11 @@ func (h *categoriesHandler) handleGetCategories(w http.ResponseWriter, r *http.R
 	errChan := make(chan error, 1)
 
 	go func() {
-		// get token from header
-		token, err := helper.GetBearerTokenFromHeader(r)
+		// check access of the authenticated user
+		_, err := auth.Authorize(ctx, user.RequireRole(user.RoleCustomer, user.RoleAdmin))
 		if err != nil {
-			statusCode = http.StatusBadRequest
-			errChan <- errInvalidToken
-			return
-		}
-
-		// check access token
-		err = checkAccessToken(ctx, h.client, token, "handleGetCategories")
-		if err != nil {
-			statusCode = http.StatusUnauthorized
-			errChan <- err
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
 			return
 		}
 
@@ -116,3 +111,101 @@ func (h *categoriesHandler) handleGetCategories(w http.ResponseWriter, r *http.R
 		})
 	}
 }
+
+func (h *categoriesHandler) handleCreateCategory(w http.ResponseWriter, r *http.Request) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 3000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[Category HTTP][handleCreateCategory] Failed to create category. Err: %s\n", err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan int64, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// read body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		// unmarshall body
+		request := categoryHTTP{}
+		err = json.Unmarshal(body, &request)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		// check access of the authenticated user, only admin
+		// can change the catalogue
+		_, err = auth.Authorize(ctx, user.RequireRole(user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// format HTTP request into service object
+		reqCategory := parseCategoryFromRequest(request, category.Category{})
+
+		categoryID, err := h.category.CreateCategory(ctx, reqCategory)
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// the name is used by other category
+			if err == category.ErrCategoryAlreadyExist {
+				statusCode = http.StatusConflict
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[Category HTTP][handleCreateCategory] Internal error from CreateCategory. Err: %s\n", err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- categoryID
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case categoryID := <-resChan:
+		resBody, err = json.Marshal(helper.ResponseEnvelope{
+			Status: "Success",
+			Data:   categoryID,
+		})
+	}
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"strconv"
@@ -10,13 +11,13 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
 	"github.com/synapsis-test/internal/category"
 	"github.com/synapsis-test/internal/user"
 )
 
 type categoryHandler struct {
 	category category.Service
-	client   user.Service
 }
 
 func (h *categoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -32,6 +33,10 @@ func (h *categoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		h.handleGetCategoryByID(w, r, categoryID)
+	case http.MethodPatch:
+		h.handleUpdateCategory(w, r, categoryID)
+	case http.MethodDelete:
+		h.handleDeleteCategory(w, r, categoryID)
 	default:
 		helper.WriteErrorResponse(w, http.StatusMethodNotAllowed, []string{errMethodNotAllowed.Error()})
 	}
@@ -65,25 +70,118 @@ func (h *categoryHandler) handleGetCategoryByID(w http.ResponseWriter, r *http.R
 	errChan := make(chan error, 1)
 
 	go func() {
-		// get token from header
-		token, err := helper.GetBearerTokenFromHeader(r)
+		// check access of the authenticated user
+		_, err := auth.Authorize(ctx, user.RequireRole(user.RoleCustomer, user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		res, err := h.category.GetCategoryByID(ctx, categoryID)
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[Category HTTP][handleGetCategoryByID] Internal error from GetCategoryByID. categoryID: %d. Err: %s\n", categoryID, err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- res
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case res := <-resChan:
+		// format product
+		var c categoryHTTP
+		c, err = formatCategory(res)
+		if err != nil {
+			return
+		}
+		// construct response data
+		resBody, err = json.Marshal(helper.ResponseEnvelope{
+			Data: c,
+		})
+	}
+}
+
+func (h *categoryHandler) handleUpdateCategory(w http.ResponseWriter, r *http.Request, categoryID int64) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 3000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[Category HTTP][handleUpdateCategory] Failed to update category. categoryID: %d, Err: %s\n", categoryID, err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan category.Category, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// read body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		// unmarshall body
+		request := categoryHTTP{}
+		err = json.Unmarshal(body, &request)
 		if err != nil {
 			statusCode = http.StatusBadRequest
-			errChan <- errInvalidToken
+			errChan <- errBadRequest
 			return
 		}
 
-		// check access token
-		err = checkAccessToken(ctx, h.client, token, "handleGetCategoryByID")
+		// check access of the authenticated user, only admin
+		// can change the catalogue
+		_, err = auth.Authorize(ctx, user.RequireRole(user.RoleAdmin))
 		if err != nil {
-			statusCode = http.StatusUnauthorized
-			errChan <- err
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
 			return
 		}
 
-		// TODO: add authorization flow with roles
+		// only the given fields are updated, the others keep
+		// their current values
+		current, err := h.category.GetCategoryByID(ctx, categoryID)
+		if err == nil {
+			current = parseCategoryFromRequest(request, current)
+			err = h.category.UpdateCategory(ctx, current)
+		}
 
-		res, err := h.category.GetCategoryByID(ctx, categoryID)
 		if err != nil {
 			// determine error and status code, by default its internal error
 			parsedErr := errInternalServer
@@ -93,16 +191,21 @@ func (h *categoryHandler) handleGetCategoryByID(w http.ResponseWriter, r *http.R
 				statusCode = http.StatusBadRequest
 			}
 
+			// the name is used by other category
+			if err == category.ErrCategoryAlreadyExist {
+				statusCode = http.StatusConflict
+			}
+
 			// log the actual error if its internal error
 			if statusCode == http.StatusInternalServerError {
-				log.Printf("[Category HTTP][handleGetCategoryByID] Internal error from GetCategoryByID. categoryID: %d. Err: %s\n", categoryID, err.Error())
+				log.Printf("[Category HTTP][handleUpdateCategory] Internal error from UpdateCategory. categoryID: %d. Err: %s\n", categoryID, err.Error())
 			}
 
 			errChan <- parsedErr
 			return
 		}
 
-		resChan <- res
+		resChan <- current
 	}()
 
 	// wait and handle main go routine
@@ -112,7 +215,7 @@ func (h *categoryHandler) handleGetCategoryByID(w http.ResponseWriter, r *http.R
 		err = errRequestTimeout
 	case err = <-errChan:
 	case res := <-resChan:
-		// format product
+		// format category
 		var c categoryHTTP
 		c, err = formatCategory(res)
 		if err != nil {
@@ -120,7 +223,98 @@ func (h *categoryHandler) handleGetCategoryByID(w http.ResponseWriter, r *http.R
 		}
 		// construct response data
 		resBody, err = json.Marshal(helper.ResponseEnvelope{
-			Data: c,
+			Status: "Success",
+			Data:   c,
+		})
+	}
+}
+
+func (h *categoryHandler) handleDeleteCategory(w http.ResponseWriter, r *http.Request, categoryID int64) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 3000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[Category HTTP][handleDeleteCategory] Failed to delete category. categoryID: %d, Err: %s\n", categoryID, err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan struct{}, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// products of the category are moved to this category
+		// if it is given
+		var reassignCategoryID int64
+		if reassignStr := r.URL.Query().Get("reassign_category_id"); reassignStr != "" {
+			id, err := strconv.ParseInt(reassignStr, 10, 64)
+			if err != nil {
+				statusCode = http.StatusBadRequest
+				errChan <- errInvalidCategoryID
+				return
+			}
+			reassignCategoryID = id
+		}
+
+		// check access of the authenticated user, only admin
+		// can change the catalogue
+		_, err := auth.Authorize(ctx, user.RequireRole(user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		err = h.category.DeleteCategory(ctx, categoryID, reassignCategoryID)
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// the category still has products
+			if err == category.ErrCategoryInUse {
+				statusCode = http.StatusConflict
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[Category HTTP][handleDeleteCategory] Internal error from DeleteCategory. categoryID: %d. Err: %s\n", categoryID, err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- struct{}{}
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case <-resChan:
+		resBody, err = json.Marshal(helper.ResponseEnvelope{
+			Status: "Success",
 		})
 	}
 }
@@ -12,6 +12,10 @@ var (
 	// not found.
 	errDataNotFound = errors.New("DATA_NOT_FOUND")
 
+	// errBadRequest is returned when the given request is
+	// bad/invalid.
+	errBadRequest = errors.New("BAD_REQUEST")
+
 	// errInternalServer is returned when there is an
 	// unexpected error encountered when processing a request.
 	errInternalServer = errors.New("INTERNAL_SERVER_ERROR")
@@ -28,13 +32,21 @@ var (
 	// invalid.
 	errInvalidCategoryID = errors.New("INVALID_CATEGORY_ID")
 
-	// errInvalidToken is returned when the given token is
-	// invalid.
-	errInvalidToken = errors.New("INVALID_TOKEN")
+	// errInvalidName is returned when the given category name
+	// is invalid.
+	errInvalidName = errors.New("INVALID_NAME")
+
+	// errCategoryAlreadyExist is returned when there is
+	// already other category with the given name.
+	errCategoryAlreadyExist = errors.New("CATEGORY_ALREADY_EXIST")
+
+	// errCategoryInUse is returned when deleting a category
+	// that still has products.
+	errCategoryInUse = errors.New("CATEGORY_IN_USE")
 
-	// errUnauthorizedAccess is returned when the request
-	// is unaothorized.
-	errUnauthorizedAccess = errors.New("UNAUTHORIZED_ACCESS")
+	// errForbiddenAccess is returned when the request is
+	// authenticated but not allowed.
+	errForbiddenAccess = errors.New("FORBIDDEN_ACCESS")
 )
 
 var (
@@ -45,7 +57,10 @@ var (
 	// and the handler should just return `errInternal` as the
 	// error instead
 	mapHTTPError = map[error]error{
-		category.ErrDataNotFound:      errDataNotFound,
-		category.ErrInvalidCategoryID: errInvalidCategoryID,
+		category.ErrDataNotFound:         errDataNotFound,
+		category.ErrInvalidCategoryID:    errInvalidCategoryID,
+		category.ErrInvalidName:          errInvalidName,
+		category.ErrCategoryAlreadyExist: errCategoryAlreadyExist,
+		category.ErrCategoryInUse:        errCategoryInUse,
 	}
 )
@@ -13,3 +13,20 @@ func formatCategory(c category.Category) (categoryHTTP, error) {
 		Description: &c.Description,
 	}, nil
 }
+
+// parseCategoryFromRequest returns the given current
+// category with the fields given in the HTTP request object
+// applied.
+func parseCategoryFromRequest(ch categoryHTTP, current category.Category) category.Category {
+	result := current
+
+	if ch.Name != nil {
+		result.Name = *ch.Name
+	}
+
+	if ch.Description != nil {
+		result.Description = *ch.Description
+	}
+
+	return result
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/synapsis-test/internal/category"
-	"github.com/synapsis-test/internal/user"
 )
 
 var (
@@ -17,8 +16,7 @@ var (
 // Handler contains category HTTP-handlers.
 type Handler struct {
 	handlers map[string]*handler
-	category  category.Service
-	client   user.Service
+	category category.Service
 }
 
 // handler is the HTTP handler wrapper.
@@ -31,6 +29,10 @@ type handler struct {
 type HandlerIdentity struct {
 	Name string
 	URL  string
+
+	// Public serves the handler without authentication,
+	// otherwise only authenticated requests are served.
+	Public bool
 }
 
 // Followings are the known HTTP handler identities
@@ -51,11 +53,10 @@ var (
 )
 
 // New creates a new Handler.
-func New(category category.Service, client user.Service, identities []HandlerIdentity) (*Handler, error) {
+func New(category category.Service, identities []HandlerIdentity) (*Handler, error) {
 	h := &Handler{
 		handlers: make(map[string]*handler),
-		category:  category,
-		client:   client,
+		category: category,
 	}
 
 	// apply identity
@@ -87,12 +88,10 @@ func (h *Handler) createHTTPHandler(configName string) (http.Handler, error) {
 	case HandlerCategories.Name:
 		httpHandler = &categoriesHandler{
 			category: h.category,
-			client:  h.client,
 		}
 	case HandlerCategory.Name:
 		httpHandler = &categoryHandler{
 			category: h.category,
-			client:  h.client,
 		}
 	default:
 		return httpHandler, errUnknownConfig
@@ -100,10 +99,15 @@ func (h *Handler) createHTTPHandler(configName string) (http.Handler, error) {
 	return httpHandler, nil
 }
 
-// Start starts all HTTP handlers.
-func (h *Handler) Start(multiplexer *mux.Router) error {
+// Start starts all HTTP handlers. Handlers that are not
+// public are wrapped with the given authenticate middleware.
+func (h *Handler) Start(multiplexer *mux.Router, authenticate mux.MiddlewareFunc) error {
 	for _, handler := range h.handlers {
-		multiplexer.Handle(handler.identity.URL, handler.h)
+		if handler.identity.Public {
+			multiplexer.Handle(handler.identity.URL, handler.h)
+			continue
+		}
+		multiplexer.Handle(handler.identity.URL, authenticate(handler.h))
 	}
 	return nil
 }
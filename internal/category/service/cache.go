@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/synapsis-test/global/cache"
+	"github.com/synapsis-test/internal/category"
+)
+
+// categoryCacheTTL is how long categories are cached.
+const categoryCacheTTL = 30 * time.Minute
+
+// categoryCachePrefix is the prefix of the cache keys of
+// categories.
+const categoryCachePrefix = "category:data:"
+
+// categoriesCacheName is the cache name of the list of
+// categories.
+const categoriesCacheName = "list"
+
+// getThroughCache reads the cached value of the given name
+// into the given destination, otherwise it loads the value
+// using the given load function and caches it.
+func (s *service) getThroughCache(ctx context.Context, name string, dst interface{}, load func() (interface{}, error)) error {
+	return s.readThrough.Get(ctx, func(ctx context.Context) (string, error) {
+		return s.formatCacheKey(ctx, name)
+	}, dst, load)
+}
+
+// invalidateCategoriesCache changes the version of
+// categories, so the next reads, including the cached
+// products, get the changes from store. The categories cached
+// in the previous versions are left to expire. The change is
+// already stored, so the failure is only logged.
+func (s *service) invalidateCategoriesCache(ctx context.Context) {
+	err := cache.BumpVersion(ctx, s.cache, category.CacheVersionKey, s.timeNow())
+	if err != nil {
+		log.Printf("[Category Service][invalidateCategoriesCache] Failed to invalidate categories cache. Err: %s\n", err.Error())
+	}
+}
+
+// formatCacheKey returns the cache key of the given name. The
+// key contains the current version of categories.
+func (s *service) formatCacheKey(ctx context.Context, name string) (string, error) {
+	version, err := cache.GetVersion(ctx, s.cache, category.CacheVersionKey)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%sv%s:%s", categoryCachePrefix, version, name), nil
+}
+
+// formatCategoryCacheName returns the cache name of the
+// category with the given category ID.
+func formatCategoryCacheName(id int64) string {
+	return fmt.Sprintf("item:%d", id)
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"strings"
 
 	"github.com/synapsis-test/internal/category"
+	"github.com/synapsis-test/internal/category/store/postgresql"
 )
 
 func (s *service) GetCategoryByID(ctx context.Context, id int64) (category.Category, error) {
@@ -12,14 +14,17 @@ func (s *service) GetCategoryByID(ctx context.Context, id int64) (category.Categ
 		return category.Category{}, category.ErrInvalidCategoryID
 	}
 
-	// get pg store client
-	pgStoreClient, err := s.pgStore.NewClient(false)
-	if err != nil {
-		return category.Category{}, err
-	}
+	var result category.Category
+	err := s.getThroughCache(ctx, formatCategoryCacheName(id), &result, func() (interface{}, error) {
+		// get pg store client
+		pgStoreClient, err := s.pgStore.NewClient(false)
+		if err != nil {
+			return nil, err
+		}
 
-	// get a product from postgre
-	result, err := pgStoreClient.GetCategoryByID(ctx, id)
+		// get a category from postgre
+		return pgStoreClient.GetCategoryByID(ctx, id)
+	})
 	if err != nil {
 		return category.Category{}, err
 	}
@@ -28,17 +33,210 @@ func (s *service) GetCategoryByID(ctx context.Context, id int64) (category.Categ
 }
 
 func (s *service) GetCategories(ctx context.Context) ([]category.Category, error) {
-	// get pg store client
-	pgStoreClient, err := s.pgStore.NewClient(false)
+	var result []category.Category
+	err := s.getThroughCache(ctx, categoriesCacheName, &result, func() (interface{}, error) {
+		// get pg store client
+		pgStoreClient, err := s.pgStore.NewClient(false)
+		if err != nil {
+			return nil, err
+		}
+
+		// get all categories from postgre
+		return pgStoreClient.GetCategories(ctx)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// get all product from postgre
-	result, err := pgStoreClient.GetCategories(ctx)
+	return result, nil
+}
+
+func (s *service) CreateCategory(ctx context.Context, reqCategory category.Category) (int64, error) {
+	// validate fields
+	reqCategory.Name = strings.TrimSpace(reqCategory.Name)
+	err := validateCategory(reqCategory)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	return result, nil
+	// update fields
+	reqCategory.CreateTime = s.timeNow()
+
+	// get pg store client using transaction
+	pgStoreClient, err := s.pgStore.NewClient(true)
+	if err != nil {
+		return 0, err
+	}
+
+	categoryID, err := s.createCategory(ctx, pgStoreClient, reqCategory)
+	if err != nil {
+		pgStoreClient.Rollback()
+		return 0, err
+	}
+
+	err = pgStoreClient.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	s.invalidateCategoriesCache(ctx)
+
+	return categoryID, nil
+}
+
+func (s *service) UpdateCategory(ctx context.Context, reqCategory category.Category) error {
+	// validate fields
+	if reqCategory.ID <= 0 {
+		return category.ErrInvalidCategoryID
+	}
+	reqCategory.Name = strings.TrimSpace(reqCategory.Name)
+	err := validateCategory(reqCategory)
+	if err != nil {
+		return err
+	}
+
+	// update fields
+	reqCategory.UpdateTime = s.timeNow()
+
+	// get pg store client using transaction
+	pgStoreClient, err := s.pgStore.NewClient(true)
+	if err != nil {
+		return err
+	}
+
+	err = s.updateCategory(ctx, pgStoreClient, reqCategory)
+	if err != nil {
+		pgStoreClient.Rollback()
+		return err
+	}
+
+	err = pgStoreClient.Commit()
+	if err != nil {
+		return err
+	}
+
+	s.invalidateCategoriesCache(ctx)
+
+	return nil
+}
+
+func (s *service) DeleteCategory(ctx context.Context, id int64, reassignCategoryID int64) error {
+	// validate the given values
+	if id <= 0 || reassignCategoryID < 0 || reassignCategoryID == id {
+		return category.ErrInvalidCategoryID
+	}
+
+	// get pg store client using transaction
+	pgStoreClient, err := s.pgStore.NewClient(true)
+	if err != nil {
+		return err
+	}
+
+	err = s.deleteCategory(ctx, pgStoreClient, id, reassignCategoryID)
+	if err != nil {
+		pgStoreClient.Rollback()
+		return err
+	}
+
+	err = pgStoreClient.Commit()
+	if err != nil {
+		return err
+	}
+
+	s.invalidateCategoriesCache(ctx)
+
+	return nil
+}
+
+// createCategory inserts the given category if its name is
+// not used by other category using the given pg store
+// client, which should use transaction.
+func (s *service) createCategory(ctx context.Context, pgStoreClient postgresql.PGStoreClient, reqCategory category.Category) (int64, error) {
+	err := checkCategoryName(ctx, pgStoreClient, reqCategory)
+	if err != nil {
+		return 0, err
+	}
+
+	return pgStoreClient.CreateCategory(ctx, reqCategory)
+}
+
+// updateCategory updates the given category if its name is
+// not used by other category using the given pg store
+// client, which should use transaction.
+func (s *service) updateCategory(ctx context.Context, pgStoreClient postgresql.PGStoreClient, reqCategory category.Category) error {
+	err := checkCategoryName(ctx, pgStoreClient, reqCategory)
+	if err != nil {
+		return err
+	}
+
+	return pgStoreClient.UpdateCategory(ctx, reqCategory)
+}
+
+// deleteCategory deletes the category with the given
+// category ID using the given pg store client, which should
+// use transaction. Products of the category are moved to the
+// given reassign category ID if it is given, otherwise the
+// category should not have any product.
+func (s *service) deleteCategory(ctx context.Context, pgStoreClient postgresql.PGStoreClient, id int64, reassignCategoryID int64) error {
+	now := s.timeNow()
+
+	// make sure the category exists before touching products
+	_, err := pgStoreClient.GetCategoryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if reassignCategoryID > 0 {
+		// the products can only be moved to existing category
+		_, err = pgStoreClient.GetCategoryByID(ctx, reassignCategoryID)
+		if err == category.ErrDataNotFound {
+			return category.ErrInvalidCategoryID
+		}
+		if err != nil {
+			return err
+		}
+
+		err = pgStoreClient.ReassignProducts(ctx, id, reassignCategoryID, now)
+		if err != nil {
+			return err
+		}
+	} else {
+		count, err := pgStoreClient.CountProductsByCategoryID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return category.ErrCategoryInUse
+		}
+	}
+
+	return pgStoreClient.DeleteCategory(ctx, id, now)
+}
+
+// checkCategoryName returns category.ErrCategoryAlreadyExist
+// if the name of the given category is used by other
+// category.
+func checkCategoryName(ctx context.Context, pgStoreClient postgresql.PGStoreClient, reqCategory category.Category) error {
+	existing, err := pgStoreClient.GetCategoryByName(ctx, reqCategory.Name)
+	if err == category.ErrDataNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.ID != reqCategory.ID {
+		return category.ErrCategoryAlreadyExist
+	}
+
+	return nil
+}
+
+// validateCategory validates fields of the given category.
+func validateCategory(reqCategory category.Category) error {
+	if reqCategory.Name == "" {
+		return category.ErrInvalidName
+	}
+
+	return nil
 }
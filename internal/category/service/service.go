@@ -3,20 +3,25 @@ package service
 import (
 	"time"
 
+	"github.com/synapsis-test/global/cache"
 	"github.com/synapsis-test/internal/category/store/postgresql"
 )
 
 // service implements user.Service.
 type service struct {
-	pgStore postgresql.PGStore
-	timeNow func() time.Time
+	pgStore     postgresql.PGStore
+	cache       cache.Cache
+	readThrough *cache.ReadThrough
+	timeNow     func() time.Time
 }
 
 // New creates a new service.
-func New(pgStore postgresql.PGStore) (*service, error) {
+func New(pgStore postgresql.PGStore, cacheClient cache.Cache) (*service, error) {
 	s := &service{
-		pgStore: pgStore,
-		timeNow: time.Now,
+		pgStore:     pgStore,
+		cache:       cacheClient,
+		readThrough: cache.NewReadThrough(cacheClient, categoryCacheTTL),
+		timeNow:     time.Now,
 	}
 
 	return s, nil
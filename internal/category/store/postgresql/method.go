@@ -4,13 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/synapsis-test/internal/category"
 )
 
 func (sc *storeClient) GetCategoryByID(ctx context.Context, id int64) (category.Category, error) {
-	query := fmt.Sprintf(queryGetCategory, "WHERE c.id = $1")
+	query := fmt.Sprintf(queryGetCategory, "WHERE c.id = $1 AND c.deleted_at IS NULL")
 	// query single row
 	var cdb categoryDB
 	err := sc.q.QueryRowx(query, id).StructScan(&cdb)
@@ -24,9 +26,24 @@ func (sc *storeClient) GetCategoryByID(ctx context.Context, id int64) (category.
 	return cdb.format(), nil
 }
 
+func (sc *storeClient) GetCategoryByName(ctx context.Context, name string) (category.Category, error) {
+	query := fmt.Sprintf(queryGetCategory, "WHERE LOWER(c.name) = LOWER($1) AND c.deleted_at IS NULL")
+	// query single row
+	var cdb categoryDB
+	err := sc.q.QueryRowx(query, name).StructScan(&cdb)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return category.Category{}, category.ErrDataNotFound
+		}
+		return category.Category{}, err
+	}
+
+	return cdb.format(), nil
+}
+
 func (sc *storeClient) GetCategories(ctx context.Context) ([]category.Category, error) {
 	// construct query
-	query := fmt.Sprintf(queryGetCategory, "")
+	query := fmt.Sprintf(queryGetCategory, "WHERE c.deleted_at IS NULL")
 
 	// prepare query
 	query, args, err := sqlx.Named(query, map[string]interface{}{})
@@ -64,3 +81,169 @@ func (sc *storeClient) GetCategories(ctx context.Context) ([]category.Category,
 
 	return categories, nil
 }
+
+func (sc *storeClient) CreateCategory(ctx context.Context, reqCategory category.Category) (int64, error) {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"name":        reqCategory.Name,
+		"description": reqCategory.Description,
+		"create_time": reqCategory.CreateTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryCreateCategory, argsKV)
+	if err != nil {
+		return 0, err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	var categoryID int64
+	err = sc.q.QueryRowx(query, args...).Scan(&categoryID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr != nil {
+			if pqErr.Code.Name() == "unique_violation" {
+				return 0, category.ErrCategoryAlreadyExist
+			}
+		}
+		return 0, err
+	}
+
+	return categoryID, nil
+}
+
+func (sc *storeClient) UpdateCategory(ctx context.Context, reqCategory category.Category) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"id":          reqCategory.ID,
+		"name":        reqCategory.Name,
+		"description": reqCategory.Description,
+		"update_time": reqCategory.UpdateTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryUpdateCategory, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	result, err := sc.q.Exec(query, args...)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr != nil {
+			if pqErr.Code.Name() == "unique_violation" {
+				return category.ErrCategoryAlreadyExist
+			}
+		}
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return category.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (sc *storeClient) DeleteCategory(ctx context.Context, id int64, deleteTime time.Time) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"id":         id,
+		"deleted_at": deleteTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryDeleteCategory, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	result, err := sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return category.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (sc *storeClient) CountProductsByCategoryID(ctx context.Context, id int64) (int64, error) {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"category_id": id,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryCountProductsByCategoryID, argsKV)
+	if err != nil {
+		return 0, err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	query = sc.q.Rebind(query)
+
+	// query single row
+	var count int64
+	err = sc.q.QueryRowx(query, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (sc *storeClient) ReassignProducts(ctx context.Context, id int64, newID int64, updateTime time.Time) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"category_id":     id,
+		"new_category_id": newID,
+		"update_time":     updateTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryReassignProducts, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	_, err = sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
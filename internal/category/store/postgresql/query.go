@@ -11,3 +11,67 @@ const queryGetCategory = `
 		category c
 	%s
 `
+
+const queryCreateCategory = `
+	INSERT INTO
+		category
+	(
+		name,
+		description,
+		create_time
+	) VALUES (
+		:name,
+		:description,
+		:create_time
+	) RETURNING
+		id
+`
+
+const queryUpdateCategory = `
+	UPDATE
+		category
+	SET
+		name = :name,
+		description = :description,
+		update_time = :update_time
+	WHERE
+		id = :id
+	AND
+		deleted_at IS NULL
+`
+
+const queryDeleteCategory = `
+	UPDATE
+		category
+	SET
+		deleted_at = :deleted_at
+	WHERE
+		id = :id
+	AND
+		deleted_at IS NULL
+`
+
+const queryCountProductsByCategoryID = `
+	SELECT
+		COUNT(*)
+	FROM
+		product p
+	WHERE
+		p.category_id = :category_id
+	AND
+		p.deleted_at IS NULL
+`
+
+// queryReassignProducts leaves deleted products as they are,
+// so they keep the category they were ordered in.
+const queryReassignProducts = `
+	UPDATE
+		product
+	SET
+		category_id = :new_category_id,
+		update_time = :update_time
+	WHERE
+		category_id = :category_id
+	AND
+		deleted_at IS NULL
+`
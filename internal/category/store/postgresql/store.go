@@ -2,6 +2,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/synapsis-test/internal/category"
 )
@@ -21,4 +22,37 @@ type PGStoreClient interface {
 
 	// GetCategories returns list of categories.
 	GetCategories(ctx context.Context) ([]category.Category, error)
+
+	// GetCategoryByName returns a category with the given
+	// name, compared case-insensitively.
+	GetCategoryByName(ctx context.Context, name string) (category.Category, error)
+
+	// CreateCategory inserts the given category.
+	//
+	// CreateCategory returns created category ID.
+	CreateCategory(ctx context.Context, category category.Category) (int64, error)
+
+	// UpdateCategory updates all the fields except ID and
+	// CreateTime of the category with the ID of the given
+	// category.
+	//
+	// It returns category.ErrDataNotFound if there is no such
+	// category or it is deleted.
+	UpdateCategory(ctx context.Context, category category.Category) error
+
+	// DeleteCategory marks the category with the given
+	// category ID as deleted at the given delete time.
+	//
+	// It returns category.ErrDataNotFound if there is no such
+	// category or it is already deleted.
+	DeleteCategory(ctx context.Context, id int64, deleteTime time.Time) error
+
+	// CountProductsByCategoryID returns the number of products
+	// that are not deleted in the given category ID.
+	CountProductsByCategoryID(ctx context.Context, id int64) (int64, error)
+
+	// ReassignProducts moves every product that is not
+	// deleted in the given category ID to the given new
+	// category ID at the given update time.
+	ReassignProducts(ctx context.Context, id int64, newID int64, updateTime time.Time) error
 }
@@ -23,4 +23,53 @@ var (
 	// ErrInvalidQuantity is returned when the given quantity is
 	// invalid.
 	ErrInvalidQuantity = errors.New("invalid quantity")
+
+	// ErrEmptyCart is returned when there is no product in
+	// the cart to be ordered.
+	ErrEmptyCart = errors.New("empty cart")
+
+	// ErrInvalidSignature is returned when the signature of
+	// the given payment notification is invalid.
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrInvalidLimit is returned when the given limit is
+	// invalid.
+	ErrInvalidLimit = errors.New("invalid limit")
+
+	// ErrInvalidStatusTransition is returned when the order
+	// status cannot be changed into the desired status.
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+	// ErrInvalidOffset is returned when the given offset is
+	// invalid.
+	ErrInvalidOffset = errors.New("invalid offset")
+
+	// ErrInvalidStatus is returned when the given status is
+	// invalid.
+	ErrInvalidStatus = errors.New("invalid status")
+
+	// ErrInvalidTimeRange is returned when the given time
+	// range is invalid.
+	ErrInvalidTimeRange = errors.New("invalid time range")
+
+	// ErrOrderNotCharged is returned when the order is not
+	// charged to the payment gateway yet.
+	ErrOrderNotCharged = errors.New("order not charged")
+
+	// ErrAmountMismatch is returned when the amount of the
+	// given payment notification differs from the total
+	// amount of the order.
+	ErrAmountMismatch = errors.New("amount mismatch")
+
+	// ErrInvalidIdempotencyKey is returned when the given
+	// idempotency key is invalid.
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+
+	// ErrIdempotencyKeyReused is returned when the given
+	// idempotency key is already used by a different request.
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused")
+
+	// ErrIdempotencyKeyInProgress is returned when the request
+	// with the given idempotency key is still in progress.
+	ErrIdempotencyKeyInProgress = errors.New("idempotency key in progress")
 )
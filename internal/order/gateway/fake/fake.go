@@ -0,0 +1,152 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/synapsis-test/internal/order"
+	"github.com/synapsis-test/internal/order/gateway"
+)
+
+// SignatureKey is the only signature key accepted by the
+// fake gateway when parsing payment notification.
+const SignatureKey = "fake-signature-key"
+
+// paymentType is the payment type of every fake transaction.
+const paymentType = "qris"
+
+// Followings are the known error returned from fake gateway.
+var (
+	errTransactionNotCancellable = errors.New("transaction cannot be cancelled")
+	errTransactionNotRefundable  = errors.New("transaction cannot be refunded")
+)
+
+// client implements gateway.PaymentGateway in memory without
+// calling any payment gateway.
+//
+// Every result only depends on the given arguments, so it is
+// suitable for tests and local development.
+type client struct {
+	mu           sync.RWMutex
+	transactions map[string]gateway.Transaction
+}
+
+// New creates a new fake gateway.
+func New() *client {
+	return &client{
+		transactions: make(map[string]gateway.Transaction),
+	}
+}
+
+func (c *client) Charge(ctx context.Context, req order.Order) (gateway.Transaction, error) {
+	tx := gateway.Transaction{
+		GatewayOrderID:       req.GatewayOrderID,
+		GatewayTransactionID: fmt.Sprintf("fake-%s", req.GatewayOrderID),
+		PaymentType:          paymentType,
+		QRString:             fmt.Sprintf("fake-qr-%s-%d", req.GatewayOrderID, req.TotalAmount),
+		Status:               order.StatusPending,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.transactions[tx.GatewayOrderID] = formatRawResponse(tx)
+
+	return c.transactions[tx.GatewayOrderID], nil
+}
+
+func (c *client) CheckStatus(ctx context.Context, gatewayOrderID string) (gateway.Transaction, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tx, ok := c.transactions[gatewayOrderID]
+	if !ok {
+		return gateway.Transaction{}, gateway.ErrTransactionNotFound
+	}
+
+	return tx, nil
+}
+
+func (c *client) Cancel(ctx context.Context, gatewayOrderID string) (gateway.Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tx, ok := c.transactions[gatewayOrderID]
+	if !ok {
+		return gateway.Transaction{}, gateway.ErrTransactionNotFound
+	}
+
+	if tx.Status != order.StatusPending {
+		return gateway.Transaction{}, errTransactionNotCancellable
+	}
+
+	tx.Status = order.StatusCancelled
+	c.transactions[gatewayOrderID] = formatRawResponse(tx)
+
+	return c.transactions[gatewayOrderID], nil
+}
+
+func (c *client) Refund(ctx context.Context, gatewayOrderID string, amount int64, reason string) (gateway.Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tx, ok := c.transactions[gatewayOrderID]
+	if !ok {
+		return gateway.Transaction{}, gateway.ErrTransactionNotFound
+	}
+
+	if tx.Status != order.StatusSettlement {
+		return gateway.Transaction{}, errTransactionNotRefundable
+	}
+
+	tx.Status = order.StatusRefunded
+	c.transactions[gatewayOrderID] = formatRawResponse(tx)
+
+	return c.transactions[gatewayOrderID], nil
+}
+
+// ParseNotification accepts notification signed with
+// SignatureKey, and its transaction status must be the
+// string representation of an order status.
+func (c *client) ParseNotification(notification order.PaymentNotification) (gateway.Transaction, error) {
+	if notification.SignatureKey != SignatureKey {
+		return gateway.Transaction{}, order.ErrInvalidSignature
+	}
+
+	tx := gateway.Transaction{
+		GatewayOrderID: notification.OrderID,
+		Status:         order.ParseStatus(notification.TransactionStatus),
+	}
+
+	// keep the known transaction up to date, so it can be
+	// checked later
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if current, ok := c.transactions[tx.GatewayOrderID]; ok && tx.Status != order.StatusUnknown {
+		current.Status = tx.Status
+		c.transactions[tx.GatewayOrderID] = formatRawResponse(current)
+	}
+
+	return tx, nil
+}
+
+// formatRawResponse returns the given transaction with its
+// raw response filled.
+func formatRawResponse(tx gateway.Transaction) gateway.Transaction {
+	tx.RawResponse = ""
+	raw, err := json.Marshal(map[string]interface{}{
+		"order_id":           tx.GatewayOrderID,
+		"transaction_id":     tx.GatewayTransactionID,
+		"payment_type":       tx.PaymentType,
+		"qr_string":          tx.QRString,
+		"transaction_status": tx.Status.String(),
+	})
+	if err == nil {
+		tx.RawResponse = string(raw)
+	}
+	return tx
+}
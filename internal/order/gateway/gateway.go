@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/synapsis-test/internal/order"
+)
+
+// ChargeExpiry is the duration of a charge to be paid before
+// it is expired.
+const ChargeExpiry = 24 * time.Hour
+
+// Followings are the known errors returned from payment
+// gateway.
+var (
+	// ErrTransactionNotFound is returned when the desired
+	// transaction is not found in the payment gateway.
+	ErrTransactionNotFound = errors.New("transaction not found")
+)
+
+// PaymentGateway charges orders and manages their
+// transaction in a payment gateway.
+type PaymentGateway interface {
+	// Charge charges the given order using its gateway
+	// order ID and returns the created transaction.
+	Charge(ctx context.Context, order order.Order) (Transaction, error)
+
+	// CheckStatus returns the current transaction of the
+	// given gateway order ID.
+	CheckStatus(ctx context.Context, gatewayOrderID string) (Transaction, error)
+
+	// Cancel cancels the transaction of the given gateway
+	// order ID.
+	Cancel(ctx context.Context, gatewayOrderID string) (Transaction, error)
+
+	// Refund refunds the given amount of the transaction of
+	// the given gateway order ID.
+	Refund(ctx context.Context, gatewayOrderID string, amount int64, reason string) (Transaction, error)
+
+	// ParseNotification verifies the given payment
+	// notification and returns the notified transaction.
+	//
+	// It returns order.ErrInvalidSignature if the
+	// notification is not sent by the payment gateway.
+	ParseNotification(notification order.PaymentNotification) (Transaction, error)
+}
+
+// Transaction denotes a transaction in the payment gateway.
+type Transaction struct {
+	GatewayOrderID       string
+	GatewayTransactionID string
+	PaymentType          string
+	QRString             string
+
+	// Status is the order status for the transaction status,
+	// it is order.StatusUnknown if the transaction status
+	// does not correspond to any order status.
+	Status order.Status
+
+	// RawResponse is the JSON-encoded response of the
+	// payment gateway.
+	RawResponse string
+}
@@ -0,0 +1,252 @@
+package midtrans
+
+import (
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	midtranssdk "github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+	"github.com/synapsis-test/internal/order"
+	"github.com/synapsis-test/internal/order/gateway"
+)
+
+// Followings are the known environment names.
+const (
+	EnvironmentSandbox    = "sandbox"
+	EnvironmentProduction = "production"
+)
+
+// Followings are the transaction status sent by Midtrans.
+const (
+	statusCapture    = "capture"
+	statusSettlement = "settlement"
+	statusPending    = "pending"
+	statusDeny       = "deny"
+	statusCancel     = "cancel"
+	statusExpire     = "expire"
+	statusFailure    = "failure"
+	statusRefund     = "refund"
+
+	fraudStatusAccept = "accept"
+)
+
+// Followings are the known error returned from Midtrans
+// gateway.
+var (
+	errMissingServerKey   = errors.New("missing midtrans server key")
+	errUnknownEnvironment = errors.New("unknown midtrans environment")
+)
+
+// client implements gateway.PaymentGateway using Midtrans
+// Core API.
+type client struct {
+	api       coreapi.Client
+	serverKey string
+}
+
+// Config denotes Midtrans gateway configuration.
+type Config struct {
+	ServerKey   string
+	Environment string
+}
+
+// New creates a new Midtrans gateway.
+//
+// Environment is sandbox if the given environment is empty.
+func New(config Config) (*client, error) {
+	if config.ServerKey == "" {
+		return nil, errMissingServerKey
+	}
+
+	var env midtranssdk.EnvironmentType
+	switch config.Environment {
+	case "", EnvironmentSandbox:
+		env = midtranssdk.Sandbox
+	case EnvironmentProduction:
+		env = midtranssdk.Production
+	default:
+		return nil, errUnknownEnvironment
+	}
+
+	c := &client{
+		serverKey: config.ServerKey,
+	}
+	c.api.New(config.ServerKey, env)
+
+	return c, nil
+}
+
+func (c *client) Charge(ctx context.Context, req order.Order) (gateway.Transaction, error) {
+	// send every order item in the charge
+	items := make([]midtranssdk.ItemDetails, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, midtranssdk.ItemDetails{
+			ID:    fmt.Sprintf("%v", item.ProductID),
+			Name:  item.ProductName,
+			Price: item.Price,
+			Qty:   int32(item.Quantity),
+		})
+	}
+
+	chargeReq := &coreapi.ChargeReq{
+		PaymentType: coreapi.PaymentTypeQris,
+		TransactionDetails: midtranssdk.TransactionDetails{
+			OrderID:  req.GatewayOrderID,
+			GrossAmt: req.TotalAmount,
+		},
+		Items: &items,
+		CustomerDetails: &midtranssdk.CustomerDetails{
+			FName: req.UserName,
+			Email: req.UserEmail,
+		},
+		CustomExpiry: &coreapi.CustomExpiry{
+			ExpiryDuration: int(gateway.ChargeExpiry / time.Minute),
+			Unit:           "minute",
+		},
+	}
+
+	res, mErr := c.api.ChargeTransaction(chargeReq)
+	if mErr != nil {
+		return gateway.Transaction{}, mErr
+	}
+
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return gateway.Transaction{}, err
+	}
+
+	return gateway.Transaction{
+		GatewayOrderID:       res.OrderID,
+		GatewayTransactionID: res.TransactionID,
+		PaymentType:          res.PaymentType,
+		QRString:             res.QRString,
+		Status:               parseTransactionStatus(res.TransactionStatus, res.FraudStatus),
+		RawResponse:          string(raw),
+	}, nil
+}
+
+func (c *client) CheckStatus(ctx context.Context, gatewayOrderID string) (gateway.Transaction, error) {
+	res, mErr := c.api.CheckTransaction(gatewayOrderID)
+	if mErr != nil {
+		return gateway.Transaction{}, parseError(mErr)
+	}
+
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return gateway.Transaction{}, err
+	}
+
+	return gateway.Transaction{
+		GatewayOrderID:       res.OrderID,
+		GatewayTransactionID: res.TransactionID,
+		PaymentType:          res.PaymentType,
+		Status:               parseTransactionStatus(res.TransactionStatus, res.FraudStatus),
+		RawResponse:          string(raw),
+	}, nil
+}
+
+func (c *client) Cancel(ctx context.Context, gatewayOrderID string) (gateway.Transaction, error) {
+	res, mErr := c.api.CancelTransaction(gatewayOrderID)
+	if mErr != nil {
+		return gateway.Transaction{}, parseError(mErr)
+	}
+
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return gateway.Transaction{}, err
+	}
+
+	return gateway.Transaction{
+		GatewayOrderID:       res.OrderID,
+		GatewayTransactionID: res.TransactionID,
+		PaymentType:          res.PaymentType,
+		Status:               parseTransactionStatus(res.TransactionStatus, res.FraudStatus),
+		RawResponse:          string(raw),
+	}, nil
+}
+
+func (c *client) Refund(ctx context.Context, gatewayOrderID string, amount int64, reason string) (gateway.Transaction, error) {
+	res, mErr := c.api.RefundTransaction(gatewayOrderID, &coreapi.RefundReq{
+		Amount: amount,
+		Reason: reason,
+	})
+	if mErr != nil {
+		return gateway.Transaction{}, parseError(mErr)
+	}
+
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return gateway.Transaction{}, err
+	}
+
+	return gateway.Transaction{
+		GatewayOrderID:       res.OrderID,
+		GatewayTransactionID: res.TransactionID,
+		PaymentType:          res.PaymentType,
+		Status:               parseTransactionStatus(res.TransactionStatus, res.FraudStatus),
+		RawResponse:          string(raw),
+	}, nil
+}
+
+func (c *client) ParseNotification(notification order.PaymentNotification) (gateway.Transaction, error) {
+	if !c.verifySignature(notification) {
+		return gateway.Transaction{}, order.ErrInvalidSignature
+	}
+
+	return gateway.Transaction{
+		GatewayOrderID: notification.OrderID,
+		Status:         parseTransactionStatus(notification.TransactionStatus, notification.FraudStatus),
+	}, nil
+}
+
+// verifySignature checks whether the signature key of the
+// given notification is signed using the server key.
+//
+// Midtrans signs notification using SHA512 of order ID,
+// status code, gross amount, and server key.
+func (c *client) verifySignature(notification order.PaymentNotification) bool {
+	hash := sha512.Sum512([]byte(notification.OrderID + notification.StatusCode + notification.GrossAmount + c.serverKey))
+	signature := hex.EncodeToString(hash[:])
+	return subtle.ConstantTimeCompare([]byte(signature), []byte(notification.SignatureKey)) == 1
+}
+
+// parseTransactionStatus returns the order status for the
+// given Midtrans transaction status and fraud status.
+//
+// It returns order.StatusUnknown if the given transaction
+// status does not correspond to any order status.
+func parseTransactionStatus(transactionStatus, fraudStatus string) order.Status {
+	switch transactionStatus {
+	case statusCapture:
+		if fraudStatus == fraudStatusAccept {
+			return order.StatusSettlement
+		}
+		return order.StatusPending
+	case statusSettlement:
+		return order.StatusSettlement
+	case statusPending:
+		return order.StatusPending
+	case statusDeny, statusCancel, statusExpire, statusFailure:
+		return order.StatusCancelled
+	case statusRefund:
+		return order.StatusRefunded
+	}
+	return order.StatusUnknown
+}
+
+// parseError returns gateway.ErrTransactionNotFound if the
+// given Midtrans error is caused by unknown transaction,
+// otherwise it returns the given error as is.
+func parseError(mErr *midtranssdk.Error) error {
+	if mErr.StatusCode == http.StatusNotFound {
+		return gateway.ErrTransactionNotFound
+	}
+	return mErr
+}
@@ -3,22 +3,31 @@ package http
 import (
 	"context"
 	"log"
+	"net/http"
 
+	"github.com/synapsis-test/internal/order"
 	"github.com/synapsis-test/internal/user"
 )
 
-// checkAccessToken checks the given access token whether it
-// is valid or not.
-func checkAccessToken(ctx context.Context, svc user.Service, token, name string) error {
-	_, err := svc.ValidateToken(ctx, token)
+// checkOrderAccess checks whether the order with the given
+// order ID belongs to the user of the given token data, or
+// the token belongs to an admin. It returns the HTTP status
+// code and error to respond with if not.
+func checkOrderAccess(ctx context.Context, svc order.Service, tokenData user.TokenData, orderID int64, name string) (int, error) {
+	current, err := svc.GetOrderByID(ctx, orderID)
 	if err != nil {
-		log.Printf("[Category HTTP][%s] Unauthorized error from ValidateToken. Err: %s\n", name, err.Error())
-		return errUnauthorizedAccess
+		if v, ok := mapHTTPError[err]; ok {
+			return http.StatusBadRequest, v
+		}
+
+		log.Printf("[Order HTTP][%s] Internal error from GetOrderByID. orderID: %d. Err: %s\n", name, orderID, err.Error())
+		return http.StatusInternalServerError, errInternalServer
 	}
 
-	// if userID != tokenData.UserID {
-	// 	return errInvalidUserID
-	// }
+	err = user.Authorize(tokenData, user.RequireOwnerOrAdmin(current.UserID))
+	if err != nil {
+		return http.StatusForbidden, errForbiddenAccess
+	}
 
-	return nil
+	return http.StatusOK, nil
 }
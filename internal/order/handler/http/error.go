@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/synapsis-test/internal/order"
+	"github.com/synapsis-test/internal/product"
 )
 
 // Followings are the known errors from order HTTP handlers.
@@ -44,13 +45,66 @@ var (
 	// invalid.
 	errInvalidQuantity = errors.New("INVALID_QUANTITY")
 
-	// errInvalidToken is returned when the given token is
+	// errEmptyCart is returned when there is no product in
+	// the cart to be ordered.
+	errEmptyCart = errors.New("EMPTY_CART")
+
+	// errInvalidSignature is returned when the signature of
+	// the given payment notification is invalid.
+	errInvalidSignature = errors.New("INVALID_SIGNATURE")
+
+	// errInvalidStatusTransition is returned when the order
+	// status cannot be changed into the desired status.
+	errInvalidStatusTransition = errors.New("INVALID_STATUS_TRANSITION")
+
+	// errOrderNotCharged is returned when the order is not
+	// charged to the payment gateway yet.
+	errOrderNotCharged = errors.New("ORDER_NOT_CHARGED")
+
+	// errAmountMismatch is returned when the amount of the
+	// given payment notification differs from the total
+	// amount of the order.
+	errAmountMismatch = errors.New("AMOUNT_MISMATCH")
+
+	// errInvalidLimit is returned when the given limit is
+	// invalid.
+	errInvalidLimit = errors.New("INVALID_LIMIT")
+
+	// errInvalidOffset is returned when the given offset is
 	// invalid.
-	errInvalidToken = errors.New("INVALID_TOKEN")
+	errInvalidOffset = errors.New("INVALID_OFFSET")
+
+	// errInvalidStatus is returned when the given status is
+	// invalid.
+	errInvalidStatus = errors.New("INVALID_STATUS")
+
+	// errInvalidTimeRange is returned when the given time
+	// range is invalid.
+	errInvalidTimeRange = errors.New("INVALID_TIME_RANGE")
+
+	// errOutOfStock is returned when the available stock of
+	// an ordered product is not enough.
+	errOutOfStock = errors.New("OUT_OF_STOCK")
+
+	// errInvalidIdempotencyKey is returned when the given
+	// idempotency key is invalid.
+	errInvalidIdempotencyKey = errors.New("INVALID_IDEMPOTENCY_KEY")
+
+	// errIdempotencyKeyReused is returned when the given
+	// idempotency key is already used by a different request.
+	errIdempotencyKeyReused = errors.New("IDEMPOTENCY_KEY_REUSED")
+
+	// errIdempotencyKeyInProgress is returned when the request
+	// with the given idempotency key is still in progress.
+	errIdempotencyKeyInProgress = errors.New("IDEMPOTENCY_KEY_IN_PROGRESS")
+
+	// errEmailNotVerified is returned when the email of the
+	// user is required to be verified but it is not.
+	errEmailNotVerified = errors.New("EMAIL_NOT_VERIFIED")
 
-	// errUnauthorizedAccess is returned when the request
-	// is unaothorized.
-	errUnauthorizedAccess = errors.New("UNAUTHORIZED_ACCESS")
+	// errForbiddenAccess is returned when the request is
+	// authenticated but not allowed.
+	errForbiddenAccess = errors.New("FORBIDDEN_ACCESS")
 )
 
 var (
@@ -61,10 +115,23 @@ var (
 	// and the handler should just return `errInternal` as the
 	// error instead
 	mapHTTPError = map[error]error{
-		order.ErrDataNotFound:     errDataNotFound,
-		order.ErrInvalidOrderID:   errInvalidOrderID,
-		order.ErrInvalidProductID: errInvalidProductID,
-		order.ErrInvalidUserID:    errInvalidUserID,
-		order.ErrInvalidQuantity:  errInvalidQuantity,
+		order.ErrDataNotFound:             errDataNotFound,
+		order.ErrInvalidOrderID:           errInvalidOrderID,
+		order.ErrInvalidProductID:         errInvalidProductID,
+		order.ErrInvalidUserID:            errInvalidUserID,
+		order.ErrInvalidQuantity:          errInvalidQuantity,
+		order.ErrEmptyCart:                errEmptyCart,
+		order.ErrInvalidSignature:         errInvalidSignature,
+		order.ErrInvalidStatusTransition:  errInvalidStatusTransition,
+		order.ErrOrderNotCharged:          errOrderNotCharged,
+		order.ErrAmountMismatch:           errAmountMismatch,
+		order.ErrInvalidLimit:             errInvalidLimit,
+		order.ErrInvalidOffset:            errInvalidOffset,
+		order.ErrInvalidStatus:            errInvalidStatus,
+		order.ErrInvalidTimeRange:         errInvalidTimeRange,
+		order.ErrInvalidIdempotencyKey:    errInvalidIdempotencyKey,
+		order.ErrIdempotencyKeyReused:     errIdempotencyKeyReused,
+		order.ErrIdempotencyKeyInProgress: errIdempotencyKeyInProgress,
+		product.ErrOutOfStock:             errOutOfStock,
 	}
 )
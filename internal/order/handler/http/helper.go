@@ -1,29 +1,110 @@
 package http
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 
 	"github.com/synapsis-test/internal/order"
+	"github.com/synapsis-test/internal/user"
 )
 
 // formatOrder formats the given order
 // into the respective HTTP-format object.
 func formatOrder(o order.Order) (orderHTTP, error) {
 	statusStr := o.Status.String()
-	// responseMidtransString := o.ResponseMidtrans
-	// responseMidtransBytes := []byte(responseMidtransString)
-	responseMidtrans := json.RawMessage([]byte(o.ResponseMidtrans))
+
+	// raw message must be a valid JSON, so leave it out when
+	// the order is not charged yet
+	var responseMidtrans *json.RawMessage
+	if o.ResponseMidtrans != "" {
+		raw := json.RawMessage([]byte(o.ResponseMidtrans))
+		responseMidtrans = &raw
+	}
+
+	items := make([]orderItemHTTP, 0, len(o.Items))
+	for i := range o.Items {
+		items = append(items, formatOrderItem(o.Items[i]))
+	}
+
 	return orderHTTP{
-		ID:               &o.ID,
-		UserID:           &o.UserID,
-		UserName:         &o.UserName,
-		UserEmail:        &o.UserEmail,
-		ProductID:        &o.ProductID,
-		ProductName:      &o.ProductName,
-		ProductPrice:     &o.ProductPrice,
-		Quantity:         &o.Quantity,
-		TotalAmount:      &o.TotalAmount,
-		Status:           &statusStr,
-		ResponseMidtrans: &responseMidtrans,
+		ID:                   &o.ID,
+		UserID:               &o.UserID,
+		UserName:             &o.UserName,
+		UserEmail:            &o.UserEmail,
+		Items:                items,
+		TotalAmount:          &o.TotalAmount,
+		Status:               &statusStr,
+		GatewayOrderID:       &o.GatewayOrderID,
+		GatewayTransactionID: &o.GatewayTransactionID,
+		PaymentType:          &o.PaymentType,
+		QRString:             &o.QRString,
+		ResponseMidtrans:     responseMidtrans,
 	}, nil
 }
+
+// formatOrderItem formats the given order item
+// into the respective HTTP-format object.
+func formatOrderItem(item order.Item) orderItemHTTP {
+	return orderItemHTTP{
+		ProductID:   &item.ProductID,
+		ProductName: &item.ProductName,
+		Price:       &item.Price,
+		Quantity:    &item.Quantity,
+	}
+}
+
+// formatOrderStatusHistory formats the given order status
+// history into the respective HTTP-format object.
+func formatOrderStatusHistory(history order.StatusHistory) orderStatusHistoryHTTP {
+	oldStatusStr := history.OldStatus.String()
+	newStatusStr := history.NewStatus.String()
+	sourceStr := history.Source.String()
+
+	// order creation has no previous status
+	var oldStatus *string
+	if history.OldStatus != order.StatusUnknown {
+		oldStatus = &oldStatusStr
+	}
+
+	return orderStatusHistoryHTTP{
+		OldStatus:  oldStatus,
+		NewStatus:  &newStatusStr,
+		Source:     &sourceStr,
+		CreateTime: &history.CreateTime,
+	}
+}
+
+// hashRequestBody returns the hex-encoded SHA-256 hash of
+// the given JSON request body. The body is canonicalised
+// first, so the same request hashes the same regardless of
+// whitespaces and the order of its fields.
+func hashRequestBody(body []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // keep numbers as they are
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return "", err
+	}
+
+	// map keys are marshalled in sorted order
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// parseSourceFromToken returns the source of the order status
+// transitions made by the user of the given token data.
+func parseSourceFromToken(tokenData user.TokenData) order.Source {
+	if tokenData.Role == user.RoleAdmin {
+		return order.SourceAdmin
+	}
+	return order.SourceUser
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/synapsis-test/internal/order"
@@ -11,14 +12,23 @@ import (
 )
 
 var (
-	errUnknownConfig = errors.New("unknown config name")
+	errUnknownConfig      = errors.New("unknown config name")
+	errMissingUserService = errors.New("missing user service")
 )
 
+// idempotencyKeyHeader is the HTTP header carrying the
+// idempotency key of a request.
+const idempotencyKeyHeader = "Idempotency-Key"
+
 // Handler contains order HTTP-handlers.
 type Handler struct {
 	handlers map[string]*handler
 	order    order.Service
-	client   user.Service
+
+	// requireVerifiedEmail only allows users with verified
+	// email to create orders, which is checked using user.
+	requireVerifiedEmail bool
+	user                 user.Service
 }
 
 // handler is the HTTP handler wrapper.
@@ -31,6 +41,10 @@ type handler struct {
 type HandlerIdentity struct {
 	Name string
 	URL  string
+
+	// Public serves the handler without authentication,
+	// otherwise only authenticated requests are served.
+	Public bool
 }
 
 // Followings are the known HTTP handler identities
@@ -48,14 +62,49 @@ var (
 		Name: "order",
 		URL:  "/v1/orders/{id}",
 	}
+
+	// HandlerOrderCancel denotes HTTP handler to cancel
+	// a order.
+	HandlerOrderCancel = HandlerIdentity{
+		Name: "order-cancel",
+		URL:  "/v1/orders/{id}/cancel",
+	}
+
+	// HandlerOrderRefund denotes HTTP handler to refund
+	// a order.
+	HandlerOrderRefund = HandlerIdentity{
+		Name: "order-refund",
+		URL:  "/v1/orders/{id}/refund",
+	}
+
+	// HandlerOrderHistory denotes HTTP handler to get
+	// status history of a order.
+	HandlerOrderHistory = HandlerIdentity{
+		Name: "order-history",
+		URL:  "/v1/orders/{id}/history",
+	}
+
+	// HandlerPaymentNotification denotes HTTP handler to
+	// receive payment notification from Midtrans.
+	HandlerPaymentNotification = HandlerIdentity{
+		Name:   "payment-notification",
+		URL:    "/v1/payments/midtrans/notification",
+		Public: true,
+	}
 )
 
 // New creates a new Handler.
-func New(order order.Service, client user.Service, identities []HandlerIdentity) (*Handler, error) {
+func New(order order.Service, identities []HandlerIdentity, options ...Option) (*Handler, error) {
 	h := &Handler{
 		handlers: make(map[string]*handler),
 		order:    order,
-		client:   client,
+	}
+
+	// apply options
+	for _, opt := range options {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
 	}
 
 	// apply identity
@@ -79,6 +128,24 @@ func New(order order.Service, client user.Service, identities []HandlerIdentity)
 	return h, nil
 }
 
+// Option controls the behavior of Handler.
+type Option func(*Handler) error
+
+// WithVerifiedEmailRequired returns Option to only allow
+// users with verified email to create orders. The email is
+// checked using the given user service at order time, as the
+// token may be issued before the email is verified.
+func WithVerifiedEmailRequired(user user.Service) Option {
+	return func(h *Handler) error {
+		if user == nil {
+			return errMissingUserService
+		}
+		h.requireVerifiedEmail = true
+		h.user = user
+		return nil
+	}
+}
+
 // createHTTPHandler creates a new HTTP handler that
 // implements http.Handler.
 func (h *Handler) createHTTPHandler(configName string) (http.Handler, error) {
@@ -86,13 +153,29 @@ func (h *Handler) createHTTPHandler(configName string) (http.Handler, error) {
 	switch configName {
 	case HandlerOrders.Name:
 		httpHandler = &ordersHandler{
-			order:  h.order,
-			client: h.client,
+			order:                h.order,
+			requireVerifiedEmail: h.requireVerifiedEmail,
+			user:                 h.user,
 		}
 	case HandlerOrder.Name:
 		httpHandler = &orderHandler{
-			order:  h.order,
-			client: h.client,
+			order: h.order,
+		}
+	case HandlerOrderCancel.Name:
+		httpHandler = &orderCancelHandler{
+			order: h.order,
+		}
+	case HandlerOrderRefund.Name:
+		httpHandler = &orderRefundHandler{
+			order: h.order,
+		}
+	case HandlerOrderHistory.Name:
+		httpHandler = &orderHistoryHandler{
+			order: h.order,
+		}
+	case HandlerPaymentNotification.Name:
+		httpHandler = &paymentNotificationHandler{
+			order: h.order,
 		}
 	default:
 		return httpHandler, errUnknownConfig
@@ -100,24 +183,57 @@ func (h *Handler) createHTTPHandler(configName string) (http.Handler, error) {
 	return httpHandler, nil
 }
 
-// Start starts all HTTP handlers.
-func (h *Handler) Start(multiplexer *mux.Router) error {
+// Start starts all HTTP handlers. Handlers that are not
+// public are wrapped with the given authenticate middleware.
+func (h *Handler) Start(multiplexer *mux.Router, authenticate mux.MiddlewareFunc) error {
 	for _, handler := range h.handlers {
-		multiplexer.Handle(handler.identity.URL, handler.h)
+		if handler.identity.Public {
+			multiplexer.Handle(handler.identity.URL, handler.h)
+			continue
+		}
+		multiplexer.Handle(handler.identity.URL, authenticate(handler.h))
 	}
 	return nil
 }
 
 type orderHTTP struct {
-	ID               *int64           `json:"id"`
-	UserID           *int64           `json:"user_id"`
-	UserName         *string          `json:"user_name"`
-	UserEmail        *string          `json:"user_email"`
-	ProductID        *int64           `json:"product_id"`
-	ProductName      *string          `json:"product_name"`
-	ProductPrice     *int64           `json:"product_price"`
-	Quantity         *int64           `json:"quantity"`
-	TotalAmount      *int64           `json:"total_amount"`
-	Status           *string          `json:"status"`
-	ResponseMidtrans *json.RawMessage `json:"response_midtrans"`
+	ID                   *int64           `json:"id"`
+	UserID               *int64           `json:"user_id"`
+	UserName             *string          `json:"user_name"`
+	UserEmail            *string          `json:"user_email"`
+	Items                []orderItemHTTP  `json:"items"`
+	TotalAmount          *int64           `json:"total_amount"`
+	Status               *string          `json:"status"`
+	GatewayOrderID       *string          `json:"gateway_order_id"`
+	GatewayTransactionID *string          `json:"gateway_transaction_id"`
+	PaymentType          *string          `json:"payment_type"`
+	QRString             *string          `json:"qr_string"`
+	ResponseMidtrans     *json.RawMessage `json:"response_midtrans"`
+}
+
+type orderItemHTTP struct {
+	ProductID   *int64  `json:"product_id"`
+	ProductName *string `json:"product_name"`
+	Price       *int64  `json:"price"`
+	Quantity    *int64  `json:"quantity"`
+}
+
+type orderStatusHistoryHTTP struct {
+	OldStatus  *string    `json:"old_status"`
+	NewStatus  *string    `json:"new_status"`
+	Source     *string    `json:"source"`
+	CreateTime *time.Time `json:"create_time"`
+}
+
+type refundHTTP struct {
+	Reason string `json:"reason"`
+}
+
+type paymentNotificationHTTP struct {
+	OrderID           string `json:"order_id"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
 }
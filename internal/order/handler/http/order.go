@@ -10,13 +10,13 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
 	"github.com/synapsis-test/internal/order"
 	"github.com/synapsis-test/internal/user"
 )
 
 type orderHandler struct {
-	order  order.Service
-	client user.Service
+	order order.Service
 }
 
 func (h *orderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -65,24 +65,14 @@ func (h *orderHandler) handleGetOrderByID(w http.ResponseWriter, r *http.Request
 	errChan := make(chan error, 1)
 
 	go func() {
-		// get token from header
-		token, err := helper.GetBearerTokenFromHeader(r)
+		// check access of the authenticated user
+		tokenData, err := auth.Authorize(ctx, user.RequireRole(user.RoleCustomer, user.RoleAdmin))
 		if err != nil {
-			statusCode = http.StatusBadRequest
-			errChan <- errInvalidToken
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
 			return
 		}
 
-		// check access token
-		err = checkAccessToken(ctx, h.client, token, "handleGetOrderByID")
-		if err != nil {
-			statusCode = http.StatusUnauthorized
-			errChan <- err
-			return
-		}
-
-		// TODO: add authorization flow with roles
-
 		res, err := h.order.GetOrderByID(ctx, orderID)
 		if err != nil {
 			// determine error and status code, by default its internal error
@@ -102,6 +92,14 @@ func (h *orderHandler) handleGetOrderByID(w http.ResponseWriter, r *http.Request
 			return
 		}
 
+		// only the owner or admin can see the order
+		err = user.Authorize(tokenData, user.RequireOwnerOrAdmin(res.UserID))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
 		resChan <- res
 	}()
 
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
+	"github.com/synapsis-test/internal/order"
+	"github.com/synapsis-test/internal/user"
+)
+
+type orderHistoryHandler struct {
+	order order.Service
+}
+
+func (h *orderHistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		log.Printf("[Order HTTP][orderHistoryHandler] Failed to parse order ID. ID: %s. Err: %s\n", vars["id"], err.Error())
+		helper.WriteErrorResponse(w, http.StatusBadRequest, []string{errInvalidOrderID.Error()})
+		return
+	}
+
+	// handle based on HTTP request method
+	switch r.Method {
+	case http.MethodGet:
+		h.handleGetOrderStatusHistory(w, r, orderID)
+	default:
+		helper.WriteErrorResponse(w, http.StatusMethodNotAllowed, []string{errMethodNotAllowed.Error()})
+	}
+}
+
+func (h *orderHistoryHandler) handleGetOrderStatusHistory(w http.ResponseWriter, r *http.Request, orderID int64) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 1000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[Order HTTP][handleGetOrderStatusHistory] Failed to get order status history. orderID: %d, Err: %s\n", orderID, err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan []order.StatusHistory, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// check access of the authenticated user
+		tokenData, err := auth.Authorize(ctx, user.RequireRole(user.RoleCustomer, user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// only the owner or admin can see the history of the order
+		code, err := checkOrderAccess(ctx, h.order, tokenData, orderID, "handleGetOrderStatusHistory")
+		if err != nil {
+			statusCode = code
+			errChan <- err
+			return
+		}
+
+		res, err := h.order.GetOrderStatusHistory(ctx, orderID)
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[Order HTTP][handleGetOrderStatusHistory] Internal error from GetOrderStatusHistory. orderID: %d. Err: %s\n", orderID, err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- res
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case res := <-resChan:
+		// format order status history
+		history := make([]orderStatusHistoryHTTP, 0, len(res))
+		for _, item := range res {
+			history = append(history, formatOrderStatusHistory(item))
+		}
+		// construct response data
+		resBody, err = json.Marshal(helper.ResponseEnvelope{
+			Data: history,
+		})
+	}
+}
@@ -6,20 +6,27 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
 	"github.com/synapsis-test/internal/order"
+	"github.com/synapsis-test/internal/product"
 	"github.com/synapsis-test/internal/user"
 )
 
 type ordersHandler struct {
-	order  order.Service
-	client user.Service
+	order                order.Service
+	requireVerifiedEmail bool
+	user                 user.Service
 }
 
 func (h *ordersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
+	case http.MethodGet:
+		h.handleGetOrders(w, r)
 	case http.MethodPost:
 		h.handleCreateOrder(w, r)
 	default:
@@ -51,7 +58,7 @@ func (h *ordersHandler) handleCreateOrder(w http.ResponseWriter, r *http.Request
 	}()
 
 	// prepare channels for main go routine
-	resChan := make(chan int64, 1)
+	resChan := make(chan []byte, 1)
 	errChan := make(chan error, 1)
 
 	go func() {
@@ -80,7 +87,64 @@ func (h *ordersHandler) handleCreateOrder(w http.ResponseWriter, r *http.Request
 			return
 		}
 
-		orderID, err := h.order.CreateOrder(ctx, reqOrder)
+		// check access of the authenticated user
+		tokenData, err := auth.Authorize(ctx, user.RequireRole(user.RoleCustomer, user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// only users with verified email can order if required,
+		// it is read from store as the token may be issued
+		// before the email is verified
+		if h.requireVerifiedEmail {
+			current, err := h.user.GetUserByID(ctx, tokenData.UserID)
+			if err != nil {
+				log.Printf("[Order HTTP][handleCreateOrder] Internal error from GetUserByID. userID: %d. Err: %s\n", tokenData.UserID, err.Error())
+				statusCode = http.StatusInternalServerError
+				errChan <- errInternalServer
+				return
+			}
+			if !current.EmailVerified {
+				statusCode = http.StatusForbidden
+				errChan <- errEmailNotVerified
+				return
+			}
+		}
+
+		// the order belongs to the caller unless stated otherwise
+		if reqOrder.UserID == 0 {
+			reqOrder.UserID = tokenData.UserID
+		}
+
+		// only the owner or admin can order from the cart
+		err = user.Authorize(tokenData, user.RequireOwnerOrAdmin(reqOrder.UserID))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// create the order at most once for the same key, and
+		// respond the same for the repeated requests
+		var res []byte
+		idempotencyKey := r.Header.Get(idempotencyKeyHeader)
+		if idempotencyKey != "" {
+			requestHash, hashErr := hashRequestBody(body)
+			if hashErr != nil {
+				statusCode = http.StatusBadRequest
+				errChan <- errBadRequest
+				return
+			}
+			res, err = h.order.CreateOrderWithIdempotencyKey(ctx, reqOrder, idempotencyKey, requestHash, formatCreateOrderResponse)
+		} else {
+			var orderID int64
+			orderID, err = h.order.CreateOrder(ctx, reqOrder)
+			if err == nil {
+				res, err = formatCreateOrderResponse(orderID)
+			}
+		}
 		if err != nil {
 			// determine error and status code, by default its internal error
 			parsedErr := errInternalServer
@@ -90,6 +154,17 @@ func (h *ordersHandler) handleCreateOrder(w http.ResponseWriter, r *http.Request
 				statusCode = http.StatusBadRequest
 			}
 
+			// ordered product is not available anymore, or the
+			// original request is still in progress
+			if err == product.ErrOutOfStock || err == order.ErrIdempotencyKeyInProgress {
+				statusCode = http.StatusConflict
+			}
+
+			// idempotency key is used by a different request
+			if err == order.ErrIdempotencyKeyReused {
+				statusCode = http.StatusUnprocessableEntity
+			}
+
 			// log the actual error if its internal error
 			if statusCode == http.StatusInternalServerError {
 				log.Printf("[Order HTTP][handleCreateOrder] Internal error from CreateOrder. Err: %s\n", err.Error())
@@ -99,7 +174,7 @@ func (h *ordersHandler) handleCreateOrder(w http.ResponseWriter, r *http.Request
 			return
 		}
 
-		resChan <- orderID
+		resChan <- res
 	}()
 
 	// wait and handle main go routine
@@ -108,13 +183,18 @@ func (h *ordersHandler) handleCreateOrder(w http.ResponseWriter, r *http.Request
 		statusCode = http.StatusGatewayTimeout
 		err = errRequestTimeout
 	case err = <-errChan:
-	case orderID := <-resChan:
-		resBody, err = json.Marshal(helper.ResponseEnvelope{
-			Data: orderID,
-		})
+	case resBody = <-resChan:
 	}
 }
 
+// formatCreateOrderResponse returns the response body of the
+// order created with the given order ID.
+func formatCreateOrderResponse(orderID int64) ([]byte, error) {
+	return json.Marshal(helper.ResponseEnvelope{
+		Data: orderID,
+	})
+}
+
 // parseOrderFromCreateRequest returns Order from the
 // given HTTP request object.
 func parseOrderFromCreateRequest(req orderHTTP) (order.Order, error) {
@@ -124,12 +204,163 @@ func parseOrderFromCreateRequest(req orderHTTP) (order.Order, error) {
 		result.UserID = *req.UserID
 	}
 
-	if req.ProductID != nil {
-		result.ProductID = *req.ProductID
+	return result, nil
+}
+
+func (h *ordersHandler) handleGetOrders(w http.ResponseWriter, r *http.Request) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 2000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[Order HTTP][handleGetOrders] Failed to get orders. Err: %s\n", err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan []order.Order, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// check access of the authenticated user
+		tokenData, err := auth.Authorize(ctx, user.RequireRole(user.RoleCustomer, user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// parsed filter
+		filter, err := parseGetOrdersFilter(r.URL.Query())
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- err
+			return
+		}
+
+		// only list the caller's orders
+		filter.UserID = tokenData.UserID
+
+		res, err := h.order.GetOrders(ctx, filter)
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[Order HTTP][handleGetOrders] Internal error from GetOrders. Err: %s\n", err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- res
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case res := <-resChan:
+		// format each orders
+		orders := make([]orderHTTP, 0)
+		for _, r := range res {
+			var o orderHTTP
+			o, err = formatOrder(r)
+			if err != nil {
+				return
+			}
+			orders = append(orders, o)
+		}
+
+		// construct response data
+		resBody, err = json.Marshal(helper.ResponseEnvelope{
+			Data: orders,
+		})
+	}
+}
+
+// parseGetOrdersFilter returns orders filter from the given
+// HTTP request query.
+//
+// Time range is given in RFC3339 format.
+func parseGetOrdersFilter(request url.Values) (order.GetOrdersFilter, error) {
+	result := order.GetOrdersFilter{}
+
+	if statusStr := request.Get("status"); statusStr != "" {
+		result.Status = order.ParseStatus(statusStr)
+		if result.Status == order.StatusUnknown {
+			return result, errInvalidStatus
+		}
+	}
+
+	if productIDStr := request.Get("product_id"); productIDStr != "" {
+		productID, err := strconv.ParseInt(productIDStr, 10, 64)
+		if err != nil {
+			return result, errInvalidProductID
+		}
+		result.ProductID = productID
+	}
+
+	if fromStr := request.Get("create_time_from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return result, errInvalidTimeRange
+		}
+		result.CreateTimeFrom = from
+	}
+
+	if toStr := request.Get("create_time_to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return result, errInvalidTimeRange
+		}
+		result.CreateTimeTo = to
 	}
 
-	if req.Quantity != nil {
-		result.Quantity = *req.Quantity
+	switch request.Get("sort") {
+	case "", "desc":
+	case "asc":
+		result.SortAscending = true
+	default:
+		return result, errBadRequest
+	}
+
+	if limitStr := request.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return result, errInvalidLimit
+		}
+		result.Limit = limit
+	}
+
+	if offsetStr := request.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			return result, errInvalidOffset
+		}
+		result.Offset = offset
 	}
 
 	return result, nil
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/order"
+)
+
+type paymentNotificationHandler struct {
+	order order.Service
+}
+
+func (h *paymentNotificationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.handlePaymentNotification(w, r)
+	default:
+		helper.WriteErrorResponse(w, http.StatusMethodNotAllowed, []string{errMethodNotAllowed.Error()})
+	}
+}
+
+func (h *paymentNotificationHandler) handlePaymentNotification(w http.ResponseWriter, r *http.Request) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 3000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error                   // stores error in this handler
+		request    paymentNotificationHTTP // stores request
+		resBody    []byte                  // stores response body to write
+		statusCode = http.StatusOK         // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[Order HTTP][handlePaymentNotification] Failed to process payment notification. orderID: %s, Err: %s\n", request.OrderID, err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan struct{}, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// read body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		// unmarshall body
+		err = json.Unmarshal(body, &request)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		err = h.order.ProcessPaymentNotification(ctx, parsePaymentNotification(request))
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// notification not signed by the payment gateway
+			if err == order.ErrInvalidSignature {
+				statusCode = http.StatusUnauthorized
+			}
+
+			// checkout is still in progress, the payment
+			// gateway retries the notification later
+			if err == order.ErrOrderNotCharged {
+				statusCode = http.StatusConflict
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[Order HTTP][handlePaymentNotification] Internal error from ProcessPaymentNotification. orderID: %s. Err: %s\n", request.OrderID, err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- struct{}{}
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case <-resChan:
+		resBody, err = json.Marshal(helper.ResponseEnvelope{
+			Data: request.OrderID,
+		})
+	}
+}
+
+// parsePaymentNotification returns payment notification
+// from the given HTTP request object.
+func parsePaymentNotification(req paymentNotificationHTTP) order.PaymentNotification {
+	return order.PaymentNotification{
+		OrderID:           req.OrderID,
+		StatusCode:        req.StatusCode,
+		GrossAmount:       req.GrossAmount,
+		SignatureKey:      req.SignatureKey,
+		TransactionStatus: req.TransactionStatus,
+		FraudStatus:       req.FraudStatus,
+	}
+}
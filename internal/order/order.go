@@ -6,28 +6,136 @@ import (
 )
 
 type Service interface {
-	// CreateOrder creates a new order and return the
-	// created order ID.
+	// CreateOrder creates a new order from every product in
+	// the user's cart, charges it in a single payment, and
+	// return the created order ID.
+	//
+	// The order is stored before it is charged, and the
+	// checked out products are removed from the cart once the
+	// charge succeeds. A failed checkout cancels both the
+	// order and its charge.
 	CreateOrder(ctx context.Context, order Order) (int64, error)
 
+	// CreateOrderWithIdempotencyKey creates a new order like
+	// CreateOrder, but at most once for the given idempotency
+	// key of the user. The response of the created order is
+	// formatted using the given respond function and returned.
+	// Repeating the request with the same key and request hash
+	// returns the original response as is.
+	//
+	// It returns ErrIdempotencyKeyReused if the key is used
+	// with a different request hash, or
+	// ErrIdempotencyKeyInProgress if the original request is
+	// still being processed.
+	CreateOrderWithIdempotencyKey(ctx context.Context, order Order, key, requestHash string, respond func(orderID int64) ([]byte, error)) ([]byte, error)
+
 	// GetOrderByID returns a order with the given order ID.
 	GetOrderByID(ctx context.Context, id int64) (Order, error)
+
+	// GetOrders returns list of orders that satisfy the given
+	// filter.
+	GetOrders(ctx context.Context, filter GetOrdersFilter) ([]Order, error)
+
+	// GetOrderByGatewayOrderID returns a order with the given
+	// order ID known by the payment gateway.
+	GetOrderByGatewayOrderID(ctx context.Context, gatewayOrderID string) (Order, error)
+
+	// ProcessPaymentNotification verifies the given payment
+	// notification and updates the status of the notified
+	// order accordingly.
+	//
+	// Processing the same notification more than once does
+	// not change the result.
+	ProcessPaymentNotification(ctx context.Context, notification PaymentNotification) error
+
+	// ReconcilePendingOrders checks at most the given limit of
+	// pending orders created before the given time against the
+	// payment gateway, oldest first, and updates their status
+	// accordingly. Pending orders whose charge has expired are
+	// cancelled, and so are orders created before the given
+	// time whose checkout never finished.
+	//
+	// It returns the number of updated orders.
+	ReconcilePendingOrders(ctx context.Context, createdBefore time.Time, limit int) (int, error)
+
+	// CancelOrder cancels the pending order with the given
+	// order ID, including its charge in the payment gateway.
+	// The given source is recorded as the one who cancels it.
+	CancelOrder(ctx context.Context, id int64, source Source) error
+
+	// RefundOrder refunds the whole amount of the settled
+	// order with the given order ID with the given reason.
+	// The given source is recorded as the one who refunds it.
+	RefundOrder(ctx context.Context, id int64, reason string, source Source) error
+
+	// GetOrderStatusHistory returns every status transition of
+	// the order with the given order ID, oldest first.
+	GetOrderStatusHistory(ctx context.Context, id int64) ([]StatusHistory, error)
 }
 
 type Order struct {
-	ID               int64
-	UserID           int64
-	UserName         string // derived
-	UserEmail        string // derived
-	ProductID        int64
-	ProductName      string // derived
-	ProductPrice     int64  // derived
-	Quantity         int64
-	TotalAmount      int64
-	Status           Status
-	ResponseMidtrans string
-	CreateTime       time.Time
-	UpdateTime       time.Time
+	ID                   int64
+	UserID               int64
+	UserName             string // derived
+	UserEmail            string // derived
+	Items                []Item
+	TotalAmount          int64
+	Status               Status
+	GatewayOrderID       string // order ID known by the payment gateway
+	GatewayTransactionID string
+	PaymentType          string
+	QRString             string
+	ResponseMidtrans     string
+	CreateTime           time.Time
+	UpdateTime           time.Time
+}
+
+// GetOrdersFilter denotes filter to get list of orders.
+type GetOrdersFilter struct {
+	UserID         int64
+	Status         Status
+	ProductID      int64
+	CreateTimeFrom time.Time // inclusive
+	CreateTimeTo   time.Time // exclusive
+
+	// SortAscending sorts orders by oldest create time
+	// first, otherwise newest first.
+	SortAscending bool
+
+	Limit  int
+	Offset int
+}
+
+// Item denotes a product line item owned by an order.
+type Item struct {
+	ID          int64
+	OrderID     int64
+	ProductID   int64
+	ProductName string // derived
+	Price       int64
+	Quantity    int64
+	CreateTime  time.Time
+}
+
+// PaymentNotification denotes a payment status notification
+// sent by the payment gateway.
+type PaymentNotification struct {
+	OrderID           string // order ID known by the payment gateway
+	StatusCode        string
+	GrossAmount       string
+	SignatureKey      string
+	TransactionStatus string
+	FraudStatus       string
+}
+
+// StatusHistory denotes a status transition of an order.
+type StatusHistory struct {
+	ID         int64
+	OrderID    int64
+	OldStatus  Status // StatusUnknown when the order is created
+	NewStatus  Status
+	Source     Source
+	CreateTime time.Time
 }
 
 // Status denotes status of a order.
@@ -39,6 +147,8 @@ const (
 	StatusSettlement Status = 1
 	StatusPending    Status = 2
 	StatusCancelled  Status = 3
+	StatusRefunded   Status = 4
+	StatusCreated    Status = 5 // not charged yet
 )
 
 var (
@@ -47,6 +157,8 @@ var (
 		StatusSettlement: {},
 		StatusPending:    {},
 		StatusCancelled:  {},
+		StatusRefunded:   {},
+		StatusCreated:    {},
 	}
 
 	// StatusName maps status to it's string representation.
@@ -54,6 +166,8 @@ var (
 		StatusSettlement: "settlement",
 		StatusPending:    "pending",
 		StatusCancelled:  "cancelled",
+		StatusRefunded:   "refunded",
+		StatusCreated:    "created",
 	}
 )
 
@@ -66,3 +180,46 @@ func (s Status) Value() int {
 func (s Status) String() string {
 	return statusName[s]
 }
+
+// ParseStatus returns status for the given string
+// representation, or StatusUnknown if there is none.
+func ParseStatus(name string) Status {
+	for status := range StatusList {
+		if status.String() == name {
+			return status
+		}
+	}
+	return StatusUnknown
+}
+
+// Source denotes source of an order status transition.
+type Source int
+
+// Followings are the known source.
+const (
+	SourceUnknown    Source = 0
+	SourceWebhook    Source = 1
+	SourceReconciler Source = 2
+	SourceUser       Source = 3
+	SourceAdmin      Source = 4
+)
+
+var (
+	// sourceName maps source to it's string representation.
+	sourceName = map[Source]string{
+		SourceWebhook:    "webhook",
+		SourceReconciler: "reconciler",
+		SourceUser:       "user",
+		SourceAdmin:      "admin",
+	}
+)
+
+// Value returns int value of a source type.
+func (s Source) Value() int {
+	return int(s)
+}
+
+// String returns string representaion of a source type.
+func (s Source) String() string {
+	return sourceName[s]
+}
@@ -0,0 +1,146 @@
+package reconciler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/synapsis-test/internal/order"
+)
+
+// Following constans are config default values.
+const (
+	defaultInterval   = 1 * time.Minute
+	defaultBatchSize  = 50
+	defaultPendingAge = 15 * time.Minute
+)
+
+// Reconciler periodically reconciles pending orders against
+// the payment gateway, so an order whose payment
+// notification is lost does not stay pending forever.
+type Reconciler struct {
+	order   order.Service
+	config  Config
+	timeNow func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Config denotes reconciler configuration
+//
+// Adding a new field should also add the corresponding default
+// value in getDefaultConfig().
+type Config struct {
+	// Interval is the duration between two reconciliations.
+	Interval time.Duration
+
+	// BatchSize is the maximum number of orders checked in a
+	// reconciliation.
+	BatchSize int
+
+	// PendingAge is the minimum age of a pending order to be
+	// checked, younger orders are left to the payment
+	// notification.
+	PendingAge time.Duration
+}
+
+// getDefaultConfig returns reconciler configuration with the
+// predefined default values.
+func getDefaultConfig() Config {
+	return Config{
+		Interval:   defaultInterval,
+		BatchSize:  defaultBatchSize,
+		PendingAge: defaultPendingAge,
+	}
+}
+
+// New creates a new reconciler.
+func New(order order.Service, options ...Option) (*Reconciler, error) {
+	r := &Reconciler{
+		order:   order,
+		config:  getDefaultConfig(),
+		timeNow: time.Now,
+	}
+
+	// apply options
+	for _, opt := range options {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Option controls the behavior of reconciler.
+type Option func(*Reconciler) error
+
+// WithConfig returns Option to set reconciler configuration.
+func WithConfig(config Config) Option {
+	return func(r *Reconciler) error {
+		if config.Interval > 0 {
+			r.config.Interval = config.Interval
+		}
+		if config.BatchSize > 0 {
+			r.config.BatchSize = config.BatchSize
+		}
+		if config.PendingAge > 0 {
+			r.config.PendingAge = config.PendingAge
+		}
+		return nil
+	}
+}
+
+// Start starts reconciling pending orders in background
+// until Stop is called.
+func (r *Reconciler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.reconcile(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the reconciler and waits for the running
+// reconciliation to finish.
+func (r *Reconciler) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	r.wg.Wait()
+}
+
+// reconcile runs a single reconciliation.
+func (r *Reconciler) reconcile(ctx context.Context) {
+	// a reconciliation should not overlap with the next one
+	ctx, cancel := context.WithTimeout(ctx, r.config.Interval)
+	defer cancel()
+
+	createdBefore := r.timeNow().Add(-r.config.PendingAge)
+	updated, err := r.order.ReconcilePendingOrders(ctx, createdBefore, r.config.BatchSize)
+	if err != nil {
+		log.Printf("[Order Reconciler] Failed to reconcile pending orders. Err: %s\n", err.Error())
+		return
+	}
+
+	if updated > 0 {
+		log.Printf("[Order Reconciler] Reconciled %d pending orders\n", updated)
+	}
+}
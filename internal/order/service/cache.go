@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/synapsis-test/internal/order"
+)
+
+// invalidateCacheTimeout is the timeout to invalidate cache
+// after a change is stored.
+const invalidateCacheTimeout = 2 * time.Second
+
+// invalidateProductsCache removes the cached products of the
+// given items after their stock is changed by an order, so
+// the next reads of the products get the stock from store.
+//
+// It runs on its own context, since the change is already
+// stored even if the request context is done, and only logs
+// the failure.
+func (s *service) invalidateProductsCache(items []order.Item) {
+	ctx, cancel := context.WithTimeout(context.Background(), invalidateCacheTimeout)
+	defer cancel()
+
+	productIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	err := s.product.InvalidateCachedProducts(ctx, productIDs)
+	if err != nil {
+		log.Printf("[Order Service][invalidateProductsCache] Failed to invalidate products cache. Err: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/synapsis-test/internal/order"
+)
+
+// maxIdempotencyKeyLength is the maximum length of an
+// idempotency key.
+const maxIdempotencyKeyLength = 255
+
+// idempotencyRecord denotes the stored state of a request
+// with an idempotency key.
+type idempotencyRecord struct {
+	RequestHash string `json:"request_hash"`
+	Done        bool   `json:"done"`     // false while in progress
+	Response    []byte `json:"response"` // original response to replay
+}
+
+func (s *service) CreateOrderWithIdempotencyKey(ctx context.Context, reqOrder order.Order, key, requestHash string, respond func(orderID int64) ([]byte, error)) ([]byte, error) {
+	// validate the given values
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return nil, order.ErrInvalidIdempotencyKey
+	}
+
+	if reqOrder.UserID <= 0 {
+		return nil, order.ErrInvalidUserID
+	}
+
+	redisKey := formatIdempotencyRedisKey(reqOrder.UserID, key)
+	record := idempotencyRecord{
+		RequestHash: requestHash,
+	}
+
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	// claim the key, only one request can hold it at a time
+	claimed, err := s.redisClient.SetNX(ctx, redisKey, recordJSON, idempotencyLockTTL).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if !claimed {
+		return s.replayIdempotentRequest(ctx, redisKey, requestHash)
+	}
+
+	// the request may outlive its context once the client
+	// gives up, so the record is saved on its own context
+	saveCtx, cancel := context.WithTimeout(context.Background(), compensationTimeout)
+	defer cancel()
+
+	// hold the key until the request finishes, otherwise a
+	// retry may create the order again
+	stopRefresh := s.refreshIdempotencyLock(redisKey)
+	orderID, err := s.CreateOrder(ctx, reqOrder)
+	stopRefresh()
+	if err != nil {
+		// release the key so the request can be retried
+		if delErr := s.redisClient.Del(saveCtx, redisKey).Err(); delErr != nil {
+			log.Printf("[Order Service][CreateOrderWithIdempotencyKey] Failed to release idempotency key. userID: %d. Err: %s\n", reqOrder.UserID, delErr.Error())
+		}
+		return nil, err
+	}
+
+	response, err := respond(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// save the response to be replayed
+	record.Done = true
+	record.Response = response
+	recordJSON, err = json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.redisClient.Set(saveCtx, redisKey, recordJSON, idempotencyKeyTTL).Err()
+	if err != nil {
+		// the order is already created, so only log it
+		log.Printf("[Order Service][CreateOrderWithIdempotencyKey] Failed to save idempotency key. userID: %d, orderID: %d. Err: %s\n", reqOrder.UserID, orderID, err.Error())
+	}
+
+	return response, nil
+}
+
+// refreshIdempotencyLock periodically extends the lock of the
+// given idempotency redis key until the returned function is
+// called.
+func (s *service) refreshIdempotencyLock(redisKey string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(idempotencyLockRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), idempotencyLockRefreshInterval)
+				err := s.redisClient.Expire(ctx, redisKey, idempotencyLockTTL).Err()
+				cancel()
+				if err != nil {
+					log.Printf("[Order Service][refreshIdempotencyLock] Failed to extend idempotency key. key: %s. Err: %s\n", redisKey, err.Error())
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// replayIdempotentRequest returns the response of the request
+// holding the given idempotency redis key.
+func (s *service) replayIdempotentRequest(ctx context.Context, redisKey, requestHash string) ([]byte, error) {
+	recordJSON, err := s.redisClient.Get(ctx, redisKey).Result()
+	if err != nil {
+		// the key is released in between, let client retry
+		if err == redis.Nil {
+			return nil, order.ErrIdempotencyKeyInProgress
+		}
+		return nil, err
+	}
+
+	var record idempotencyRecord
+	err = json.Unmarshal([]byte(recordJSON), &record)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, order.ErrIdempotencyKeyReused
+	}
+
+	if !record.Done {
+		return nil, order.ErrIdempotencyKeyInProgress
+	}
+
+	return record.Response, nil
+}
+
+// formatIdempotencyRedisKey returns the redis key of the
+// given idempotency key of the given user.
+func formatIdempotencyRedisKey(userID int64, key string) string {
+	return fmt.Sprintf("order:idempotency:%d:%s", userID, key)
+}
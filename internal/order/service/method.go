@@ -2,10 +2,11 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"log"
 
 	"github.com/synapsis-test/internal/order"
+	"github.com/synapsis-test/internal/order/gateway"
 )
 
 func (s *service) CreateOrder(ctx context.Context, reqOrder order.Order) (int64, error) {
@@ -20,55 +21,193 @@ func (s *service) CreateOrder(ctx context.Context, reqOrder order.Order) (int64,
 		return 0, err
 	}
 
-	// validate order product in cart
-	valid := false
-	for _, cart := range carts {
-		fmt.Println("cart.ProductID", cart.ProductID)
-		fmt.Println("reqOrder.ProductID", reqOrder.ProductID)
-		if cart.ProductID == reqOrder.ProductID {
-			valid = true
-			reqOrder.TotalAmount = reqOrder.Quantity * cart.ProductPrice
-			reqOrder.ProductName = cart.ProductName
-			reqOrder.ProductPrice = cart.ProductPrice
-			break
-		}
-
-	}
-	fmt.Println("valid", valid)
-	if !valid {
-		return 0, order.ErrInvalidOrderID
+	if len(carts) == 0 {
+		return 0, order.ErrEmptyCart
 	}
 
 	// update fields
 	reqOrder.CreateTime = s.timeNow()
+	reqOrder.Status = order.StatusCreated
+	reqOrder.TotalAmount = 0
 
-	// get pg store client without transaction
-	pgStoreClient, err := s.pgStore.NewClient(false)
+	// construct order items from every product in cart
+	reqOrder.Items = make([]order.Item, 0, len(carts))
+	for _, cart := range carts {
+		item := order.Item{
+			ProductID:   cart.ProductID,
+			ProductName: cart.ProductName,
+			Price:       cart.ProductPrice,
+			Quantity:    cart.Quantity,
+			CreateTime:  reqOrder.CreateTime,
+		}
+
+		err = validateOrderItem(item)
+		if err != nil {
+			return 0, err
+		}
+
+		reqOrder.Items = append(reqOrder.Items, item)
+		reqOrder.TotalAmount += item.Price * item.Quantity
+	}
+
+	// create the order before charging it, so there is never
+	// a charge without an order
+	reqOrder.ID, err = s.createOrder(ctx, reqOrder)
 	if err != nil {
 		return 0, err
 	}
+	reqOrder.GatewayOrderID = formatGatewayOrderID(reqOrder.ID)
 
-	resPayment, err := s.payment(reqOrder)
+	// charge the order using gateway order ID derived from
+	// the created order ID
+	tx, err := s.gateway.Charge(ctx, reqOrder)
 	if err != nil {
+		s.compensateCreateOrder(reqOrder)
 		return 0, err
 	}
 
-	jsonData, err := json.Marshal(resPayment)
+	// update payment fields
+	reqOrder.GatewayTransactionID = tx.GatewayTransactionID
+	reqOrder.PaymentType = tx.PaymentType
+	reqOrder.QRString = tx.QRString
+	reqOrder.ResponseMidtrans = tx.RawResponse
+
+	// mark the order as waiting to be paid
+	err = s.confirmOrder(ctx, reqOrder)
 	if err != nil {
+		s.compensateCreateOrder(reqOrder)
 		return 0, err
 	}
 
-	reqOrder.ResponseMidtrans = string(jsonData)
+	return reqOrder.ID, nil
+}
+
+// createOrder creates the given order with its items in
+// created status, and reserves stock of the ordered products.
+func (s *service) createOrder(ctx context.Context, reqOrder order.Order) (int64, error) {
+	// get pg store client using transaction
+	pgStoreClient, err := s.pgStore.NewClient(true)
+	if err != nil {
+		return 0, err
+	}
 
 	// create order in pgstore
 	orderID, err := pgStoreClient.CreateOrder(ctx, reqOrder)
 	if err != nil {
+		pgStoreClient.Rollback()
+		return 0, err
+	}
+
+	// create order items in pgstore
+	err = pgStoreClient.CreateOrderItems(ctx, orderID, reqOrder.Items)
+	if err != nil {
+		pgStoreClient.Rollback()
+		return 0, err
+	}
+
+	// store the gateway order ID up front, so notification of
+	// the order can be recognized before it is confirmed
+	reqOrder.ID = orderID
+	reqOrder.GatewayOrderID = formatGatewayOrderID(orderID)
+	err = pgStoreClient.UpdateOrderPayment(ctx, reqOrder)
+	if err != nil {
+		pgStoreClient.Rollback()
+		return 0, err
+	}
+
+	// reserve stock of the ordered products, concurrent
+	// checkouts of the last unit will fail here
+	err = pgStoreClient.ReserveProductStocks(ctx, reqOrder.Items)
+	if err != nil {
+		pgStoreClient.Rollback()
 		return 0, err
 	}
 
+	// record the initial status of the order
+	err = pgStoreClient.CreateOrderStatusHistory(ctx, order.StatusHistory{
+		OrderID:    orderID,
+		OldStatus:  order.StatusUnknown,
+		NewStatus:  reqOrder.Status,
+		Source:     order.SourceUser,
+		CreateTime: reqOrder.CreateTime,
+	})
+	if err != nil {
+		pgStoreClient.Rollback()
+		return 0, err
+	}
+
+	err = pgStoreClient.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	s.invalidateProductsCache(reqOrder.Items)
+
 	return orderID, nil
 }
 
+// confirmOrder stores payment data of the given charged
+// order, marks it as pending and removes the ordered
+// products from the cart.
+func (s *service) confirmOrder(ctx context.Context, reqOrder order.Order) error {
+	// get pg store client using transaction
+	pgStoreClient, err := s.pgStore.NewClient(true)
+	if err != nil {
+		return err
+	}
+
+	// update order payment in pgstore
+	err = pgStoreClient.UpdateOrderPayment(ctx, reqOrder)
+	if err != nil {
+		pgStoreClient.Rollback()
+		return err
+	}
+
+	// mark the order as waiting to be paid
+	_, err = s.transitOrderStatus(ctx, pgStoreClient, reqOrder, order.StatusPending, order.SourceUser)
+	if err != nil {
+		pgStoreClient.Rollback()
+		return err
+	}
+
+	// remove ordered products from cart
+	productIDs := make([]int64, 0, len(reqOrder.Items))
+	for _, item := range reqOrder.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	err = pgStoreClient.DeleteProductCarts(ctx, reqOrder.UserID, productIDs)
+	if err != nil {
+		pgStoreClient.Rollback()
+		return err
+	}
+
+	return pgStoreClient.Commit()
+}
+
+// compensateCreateOrder cancels the charge and the given
+// created order after a failed checkout.
+//
+// It runs on its own context, since the request context
+// may already be done, and only logs failures since the
+// checkout has already failed.
+func (s *service) compensateCreateOrder(reqOrder order.Order) {
+	ctx, cancel := context.WithTimeout(context.Background(), compensationTimeout)
+	defer cancel()
+
+	// the charge may or may not reach the payment gateway
+	_, err := s.gateway.Cancel(ctx, reqOrder.GatewayOrderID)
+	if err != nil && err != gateway.ErrTransactionNotFound {
+		log.Printf("[Order Service][CreateOrder] Failed to cancel charge. orderID: %d. Err: %s\n", reqOrder.ID, err.Error())
+	}
+
+	// cancel the order and release its stock reservation
+	err = s.updateOrderStatus(ctx, reqOrder, order.StatusCancelled, order.SourceUser)
+	if err != nil {
+		log.Printf("[Order Service][CreateOrder] Failed to cancel order. orderID: %d. Err: %s\n", reqOrder.ID, err.Error())
+	}
+}
+
 func (s *service) GetOrderByID(ctx context.Context, id int64) (order.Order, error) {
 	// validate id
 	if id <= 0 {
@@ -81,15 +220,104 @@ func (s *service) GetOrderByID(ctx context.Context, id int64) (order.Order, erro
 		return order.Order{}, err
 	}
 
-	// get a product from postgre
+	// get a order from postgre
 	result, err := pgStoreClient.GetOrderByID(ctx, id)
 	if err != nil {
 		return order.Order{}, err
 	}
 
+	// get the order items from postgre
+	result.Items, err = pgStoreClient.GetOrderItemsByOrderID(ctx, id)
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	return result, nil
+}
+
+func (s *service) GetOrders(ctx context.Context, filter order.GetOrdersFilter) ([]order.Order, error) {
+	// validate filter
+	err := validateGetOrdersFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// use default limit if not given
+	if filter.Limit == 0 {
+		filter.Limit = defaultGetOrdersLimit
+	}
+
+	// get pg store client
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return nil, err
+	}
+
+	// get orders from postgre
+	result, err := pgStoreClient.GetOrders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// get items of every orders from postgre
+	orderIDs := make([]int64, 0, len(result))
+	for _, o := range result {
+		orderIDs = append(orderIDs, o.ID)
+	}
+
+	items, err := pgStoreClient.GetOrderItemsByOrderIDs(ctx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// group items by its order
+	itemsByOrderID := make(map[int64][]order.Item)
+	for _, item := range items {
+		itemsByOrderID[item.OrderID] = append(itemsByOrderID[item.OrderID], item)
+	}
+
+	for i := range result {
+		result[i].Items = itemsByOrderID[result[i].ID]
+	}
+
+	return result, nil
+}
+
+func (s *service) GetOrderByGatewayOrderID(ctx context.Context, gatewayOrderID string) (order.Order, error) {
+	// validate id
+	if gatewayOrderID == "" {
+		return order.Order{}, order.ErrInvalidOrderID
+	}
+
+	// get pg store client
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	// get a order from postgre
+	result, err := pgStoreClient.GetOrderByGatewayOrderID(ctx, gatewayOrderID)
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	// get the order items from postgre
+	result.Items, err = pgStoreClient.GetOrderItemsByOrderID(ctx, result.ID)
+	if err != nil {
+		return order.Order{}, err
+	}
+
 	return result, nil
 }
 
+// formatGatewayOrderID returns the order ID known by the
+// payment gateway for the given order ID.
+//
+// Order ID is unique, so is the returned gateway order ID.
+func formatGatewayOrderID(orderID int64) string {
+	return fmt.Sprintf("%s-%d", gatewayOrderIDPrefix, orderID)
+}
+
 // validateOrder validates fields of the given
 // order.
 func validateOrder(reqOrder order.Order) error {
@@ -97,11 +325,41 @@ func validateOrder(reqOrder order.Order) error {
 		return order.ErrInvalidUserID
 	}
 
-	if reqOrder.ProductID <= 0 {
+	return nil
+}
+
+// validateGetOrdersFilter validates fields of the given
+// filter.
+func validateGetOrdersFilter(filter order.GetOrdersFilter) error {
+	if filter.Status != order.StatusUnknown {
+		if _, ok := order.StatusList[filter.Status]; !ok {
+			return order.ErrInvalidStatus
+		}
+	}
+
+	if !filter.CreateTimeFrom.IsZero() && !filter.CreateTimeTo.IsZero() && !filter.CreateTimeFrom.Before(filter.CreateTimeTo) {
+		return order.ErrInvalidTimeRange
+	}
+
+	if filter.Limit < 0 || filter.Limit > maxGetOrdersLimit {
+		return order.ErrInvalidLimit
+	}
+
+	if filter.Offset < 0 {
+		return order.ErrInvalidOffset
+	}
+
+	return nil
+}
+
+// validateOrderItem validates fields of the given
+// order item.
+func validateOrderItem(item order.Item) error {
+	if item.ProductID <= 0 {
 		return order.ErrInvalidProductID
 	}
 
-	if reqOrder.Quantity <= 0 {
+	if item.Quantity <= 0 {
 		return order.ErrInvalidQuantity
 	}
 
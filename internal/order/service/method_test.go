@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/synapsis-test/internal/order"
+	"github.com/synapsis-test/internal/order/gateway"
+	"github.com/synapsis-test/internal/order/gateway/fake"
+	"github.com/synapsis-test/internal/product"
+)
+
+// testUserID is the user who orders in the tests.
+const testUserID = 7
+
+// newTestService returns a service using the given store, the
+// given carts of testUserID and the fake payment gateway.
+func newTestService(t *testing.T, store *fakeStore, carts []product.ProductCart) (*service, *fakeProductService, gateway.PaymentGateway) {
+	t.Helper()
+
+	// the cart is also stored, so removing it can be checked
+	for _, cart := range carts {
+		store.data.carts[testUserID] = append(store.data.carts[testUserID], cart.ProductID)
+	}
+
+	paymentGateway := fake.New()
+	productSvc := &fakeProductService{
+		carts: map[int64][]product.ProductCart{testUserID: carts},
+	}
+
+	s, err := New(store, productSvc, paymentGateway, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.timeNow = func() time.Time { return now }
+
+	return s, productSvc, paymentGateway
+}
+
+// testCarts returns the cart of testUserID with two products.
+func testCarts() []product.ProductCart {
+	return []product.ProductCart{
+		{UserID: testUserID, ProductID: 1, ProductName: "Shirt", ProductPrice: 50000, Quantity: 2},
+		{UserID: testUserID, ProductID: 2, ProductName: "Hat", ProductPrice: 25000, Quantity: 1},
+	}
+}
+
+func TestCreateOrder(t *testing.T) {
+	store := newFakeStore()
+	store.data.stocks[1] = 5
+	store.data.stocks[2] = 1
+	s, productSvc, paymentGateway := newTestService(t, store, testCarts())
+	ctx := context.Background()
+
+	orderID, err := s.CreateOrder(ctx, order.Order{UserID: testUserID})
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+
+	got, err := s.GetOrderByID(ctx, orderID)
+	if err != nil {
+		t.Fatalf("GetOrderByID() error = %v", err)
+	}
+
+	// the order waits to be paid through the gateway
+	if got.Status != order.StatusPending {
+		t.Errorf("status = %s, want %s", got.Status, order.StatusPending)
+	}
+	if got.TotalAmount != 125000 {
+		t.Errorf("total amount = %d, want 125000", got.TotalAmount)
+	}
+	if got.GatewayOrderID != formatGatewayOrderID(orderID) {
+		t.Errorf("gateway order ID = %s, want %s", got.GatewayOrderID, formatGatewayOrderID(orderID))
+	}
+	if got.GatewayTransactionID == "" || got.PaymentType == "" || !strings.HasPrefix(got.QRString, "fake-qr-") {
+		t.Errorf("payment = %q, %q, %q, want the fake charge", got.GatewayTransactionID, got.PaymentType, got.QRString)
+	}
+	if len(got.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(got.Items))
+	}
+	if got.Items[0].ProductID != 1 || got.Items[0].Price != 50000 || got.Items[0].Quantity != 2 {
+		t.Errorf("first item = %+v, want product 1 priced 50000 of 2", got.Items[0])
+	}
+
+	// the charge is known by the gateway
+	tx, err := paymentGateway.CheckStatus(ctx, got.GatewayOrderID)
+	if err != nil {
+		t.Fatalf("CheckStatus() error = %v", err)
+	}
+	if tx.Status != order.StatusPending {
+		t.Errorf("transaction status = %s, want %s", tx.Status, order.StatusPending)
+	}
+
+	// the stock is reserved and the cart is emptied
+	if store.data.stocks[1] != 3 || store.data.reserved[1] != 2 {
+		t.Errorf("product 1 stock = %d reserved %d, want 3 reserved 2", store.data.stocks[1], store.data.reserved[1])
+	}
+	if store.data.stocks[2] != 0 || store.data.reserved[2] != 1 {
+		t.Errorf("product 2 stock = %d reserved %d, want 0 reserved 1", store.data.stocks[2], store.data.reserved[2])
+	}
+	if len(store.data.carts[testUserID]) != 0 {
+		t.Errorf("cart = %v, want empty", store.data.carts[testUserID])
+	}
+
+	// only the cache of the ordered products is invalidated
+	if len(productSvc.invalidatedIDs) != 2 || productSvc.invalidatedIDs[0] != 1 || productSvc.invalidatedIDs[1] != 2 {
+		t.Errorf("invalidated products = %v, want [1 2]", productSvc.invalidatedIDs)
+	}
+
+	// every transition is recorded
+	histories, err := s.GetOrderStatusHistory(ctx, orderID)
+	if err != nil {
+		t.Fatalf("GetOrderStatusHistory() error = %v", err)
+	}
+	wantHistories := []order.StatusHistory{
+		{OldStatus: order.StatusUnknown, NewStatus: order.StatusCreated},
+		{OldStatus: order.StatusCreated, NewStatus: order.StatusPending},
+	}
+	if len(histories) != len(wantHistories) {
+		t.Fatalf("got %d status histories, want %d", len(histories), len(wantHistories))
+	}
+	for i, want := range wantHistories {
+		if histories[i].OldStatus != want.OldStatus || histories[i].NewStatus != want.NewStatus {
+			t.Errorf("status history %d = %s to %s, want %s to %s", i, histories[i].OldStatus, histories[i].NewStatus, want.OldStatus, want.NewStatus)
+		}
+	}
+}
+
+func TestCreateOrderInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  int64
+		carts   []product.ProductCart
+		wantErr error
+	}{
+		{name: "no user", userID: 0, carts: testCarts(), wantErr: order.ErrInvalidUserID},
+		{name: "empty cart", userID: testUserID, carts: nil, wantErr: order.ErrEmptyCart},
+		{
+			name:   "invalid quantity",
+			userID: testUserID,
+			carts: []product.ProductCart{
+				{UserID: testUserID, ProductID: 1, ProductPrice: 50000, Quantity: 0},
+			},
+			wantErr: order.ErrInvalidQuantity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			store.data.stocks[1] = 5
+			store.data.stocks[2] = 5
+			s, _, _ := newTestService(t, store, tt.carts)
+
+			_, err := s.CreateOrder(context.Background(), order.Order{UserID: tt.userID})
+			if err != tt.wantErr {
+				t.Errorf("CreateOrder() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(store.data.orders) != 0 {
+				t.Errorf("stored %d orders, want none", len(store.data.orders))
+			}
+		})
+	}
+}
+
+func TestCreateOrderOutOfStock(t *testing.T) {
+	store := newFakeStore()
+	store.data.stocks[1] = 5
+	store.data.stocks[2] = 0
+	s, productSvc, paymentGateway := newTestService(t, store, testCarts())
+	ctx := context.Background()
+
+	_, err := s.CreateOrder(ctx, order.Order{UserID: testUserID})
+	if err != product.ErrOutOfStock {
+		t.Fatalf("CreateOrder() error = %v, want %v", err, product.ErrOutOfStock)
+	}
+
+	// nothing is stored nor charged
+	if len(store.data.orders) != 0 {
+		t.Errorf("stored %d orders, want none", len(store.data.orders))
+	}
+	if store.data.stocks[1] != 5 || store.data.reserved[1] != 0 {
+		t.Errorf("product 1 stock = %d reserved %d, want 5 reserved 0", store.data.stocks[1], store.data.reserved[1])
+	}
+	_, err = paymentGateway.CheckStatus(ctx, formatGatewayOrderID(1))
+	if err != gateway.ErrTransactionNotFound {
+		t.Errorf("CheckStatus() error = %v, want %v", err, gateway.ErrTransactionNotFound)
+	}
+	if len(productSvc.invalidatedIDs) != 0 {
+		t.Errorf("invalidated products = %v, want none", productSvc.invalidatedIDs)
+	}
+}
+
+func TestCreateOrderCompensation(t *testing.T) {
+	store := newFakeStore()
+	store.data.stocks[1] = 5
+	store.data.stocks[2] = 1
+	errStore := errors.New("store unavailable")
+	store.errDeleteProductCarts = errStore
+	s, _, paymentGateway := newTestService(t, store, testCarts())
+	ctx := context.Background()
+
+	// the order fails to be confirmed after it is charged
+	_, err := s.CreateOrder(ctx, order.Order{UserID: testUserID})
+	if err != errStore {
+		t.Fatalf("CreateOrder() error = %v, want %v", err, errStore)
+	}
+
+	// the order and its charge are cancelled
+	orders, err := s.GetOrders(ctx, order.GetOrdersFilter{UserID: testUserID})
+	if err != nil {
+		t.Fatalf("GetOrders() error = %v", err)
+	}
+	if len(orders) != 1 {
+		t.Fatalf("got %d orders, want 1", len(orders))
+	}
+	if orders[0].Status != order.StatusCancelled {
+		t.Errorf("status = %s, want %s", orders[0].Status, order.StatusCancelled)
+	}
+
+	tx, err := paymentGateway.CheckStatus(ctx, orders[0].GatewayOrderID)
+	if err != nil {
+		t.Fatalf("CheckStatus() error = %v", err)
+	}
+	if tx.Status != order.StatusCancelled {
+		t.Errorf("transaction status = %s, want %s", tx.Status, order.StatusCancelled)
+	}
+
+	// the reserved stock is released and the cart is kept
+	if store.data.stocks[1] != 5 || store.data.reserved[1] != 0 {
+		t.Errorf("product 1 stock = %d reserved %d, want 5 reserved 0", store.data.stocks[1], store.data.reserved[1])
+	}
+	if store.data.stocks[2] != 1 || store.data.reserved[2] != 0 {
+		t.Errorf("product 2 stock = %d reserved %d, want 1 reserved 0", store.data.stocks[2], store.data.reserved[2])
+	}
+	if len(store.data.carts[testUserID]) != 2 {
+		t.Errorf("cart = %v, want both products", store.data.carts[testUserID])
+	}
+}
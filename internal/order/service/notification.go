@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/synapsis-test/internal/order"
+)
+
+func (s *service) ProcessPaymentNotification(ctx context.Context, notification order.PaymentNotification) error {
+	// validate the given values
+	if notification.OrderID == "" {
+		return order.ErrInvalidOrderID
+	}
+
+	// verify and parse the notification
+	tx, err := s.gateway.ParseNotification(notification)
+	if err != nil {
+		return err
+	}
+
+	// get pg store client without transaction
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return err
+	}
+
+	// get the notified order, unknown order will be rejected
+	current, err := pgStoreClient.GetOrderByGatewayOrderID(ctx, notification.OrderID)
+	if err != nil {
+		return err
+	}
+
+	// checkout of the order is still in progress, reject it so
+	// the payment gateway retries the notification later
+	if current.Status == order.StatusCreated {
+		return order.ErrOrderNotCharged
+	}
+
+	// nothing to update for unhandled transaction status
+	if tx.Status == order.StatusUnknown {
+		return nil
+	}
+
+	// duplicate notification, order is already up to date
+	if current.Status == tx.Status {
+		return nil
+	}
+
+	// only settle the order if the whole amount is paid
+	if tx.Status == order.StatusSettlement {
+		amount, err := parseGrossAmount(notification.GrossAmount)
+		if err != nil || amount != current.TotalAmount {
+			log.Printf("[Order Service][ProcessPaymentNotification] Rejected mismatched amount. orderID: %d, amount: %s, totalAmount: %d\n", current.ID, notification.GrossAmount, current.TotalAmount)
+			return order.ErrAmountMismatch
+		}
+	}
+
+	// stale notification, e.g. pending notification arrives
+	// after the settlement one, the order is already ahead
+	if !canTransitStatus(current.Status, tx.Status) {
+		log.Printf("[Order Service][ProcessPaymentNotification] Ignored illegal status transition. orderID: %d, from: %s, to: %s\n", current.ID, current.Status, tx.Status)
+		return nil
+	}
+
+	return s.updateOrderStatus(ctx, current, tx.Status, order.SourceWebhook)
+}
+
+// parseGrossAmount parses the given gross amount of a payment
+// notification, e.g. "10000.00". Rupiah has no minor unit, so
+// the fraction must be zero.
+func parseGrossAmount(grossAmount string) (int64, error) {
+	integer, fraction, _ := strings.Cut(grossAmount, ".")
+	if strings.Trim(fraction, "0") != "" {
+		return 0, order.ErrAmountMismatch
+	}
+
+	return strconv.ParseInt(integer, 10, 64)
+}
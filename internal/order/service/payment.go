@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+
+	"github.com/synapsis-test/internal/order"
+)
+
+func (s *service) CancelOrder(ctx context.Context, id int64, source order.Source) error {
+	// validate id
+	if id <= 0 {
+		return order.ErrInvalidOrderID
+	}
+
+	// get pg store client without transaction
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return err
+	}
+
+	// get current order
+	current, err := pgStoreClient.GetOrderByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// only order waiting to be paid can be cancelled, order
+	// whose checkout is still in progress is cancelled by
+	// the checkout itself when it fails
+	if current.Status == order.StatusCreated || !canTransitStatus(current.Status, order.StatusCancelled) {
+		return order.ErrInvalidStatusTransition
+	}
+
+	// cancel the charge in payment gateway
+	_, err = s.gateway.Cancel(ctx, current.GatewayOrderID)
+	if err != nil {
+		return err
+	}
+
+	return s.updateOrderStatus(ctx, current, order.StatusCancelled, source)
+}
+
+func (s *service) RefundOrder(ctx context.Context, id int64, reason string, source order.Source) error {
+	// validate id
+	if id <= 0 {
+		return order.ErrInvalidOrderID
+	}
+
+	// get pg store client without transaction
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return err
+	}
+
+	// get current order
+	current, err := pgStoreClient.GetOrderByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// only paid order can be refunded
+	if !canTransitStatus(current.Status, order.StatusRefunded) {
+		return order.ErrInvalidStatusTransition
+	}
+
+	// refund the whole amount in payment gateway
+	_, err = s.gateway.Refund(ctx, current.GatewayOrderID, current.TotalAmount, reason)
+	if err != nil {
+		return err
+	}
+
+	return s.updateOrderStatus(ctx, current, order.StatusRefunded, source)
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/synapsis-test/internal/order"
+	"github.com/synapsis-test/internal/order/gateway"
+	"github.com/synapsis-test/internal/order/store/postgresql"
+)
+
+func (s *service) ReconcilePendingOrders(ctx context.Context, createdBefore time.Time, limit int) (int, error) {
+	// validate the given values
+	if limit <= 0 {
+		return 0, order.ErrInvalidLimit
+	}
+
+	// get pg store client without transaction
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return 0, err
+	}
+
+	// cancel orders left behind by unfinished checkouts first
+	updated, err := s.cancelAbandonedOrders(ctx, pgStoreClient, createdBefore, limit)
+	if err != nil {
+		return updated, err
+	}
+
+	// get the pending orders least recently checked, so the
+	// orders are checked in turn even if there are more
+	// pending orders than the limit
+	orders, err := pgStoreClient.GetOrdersToReconcile(ctx, order.StatusPending, createdBefore, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, current := range orders {
+		// stop early, the remaining orders will be checked on
+		// the next reconciliation
+		if ctx.Err() != nil {
+			return updated, ctx.Err()
+		}
+
+		status, err := s.checkOrderStatus(ctx, current)
+
+		// move the order to the back of the queue, even if the
+		// check failed, so it does not block the others
+		markErr := pgStoreClient.UpdateOrderReconcileTime(ctx, current.ID, s.timeNow())
+		if markErr != nil {
+			log.Printf("[Order Service][ReconcilePendingOrders] Failed to mark order as reconciled. orderID: %d. Err: %s\n", current.ID, markErr.Error())
+		}
+
+		if err != nil {
+			log.Printf("[Order Service][ReconcilePendingOrders] Failed to check order status. orderID: %d. Err: %s\n", current.ID, err.Error())
+			continue
+		}
+
+		// order is still waiting to be paid
+		if status == order.StatusUnknown || status == current.Status {
+			continue
+		}
+
+		if !canTransitStatus(current.Status, status) {
+			log.Printf("[Order Service][ReconcilePendingOrders] Ignored illegal status transition. orderID: %d, from: %s, to: %s\n", current.ID, current.Status, status)
+			continue
+		}
+
+		err = s.updateOrderStatus(ctx, current, status, order.SourceReconciler)
+		if err != nil {
+			log.Printf("[Order Service][ReconcilePendingOrders] Failed to update order status. orderID: %d. Err: %s\n", current.ID, err.Error())
+			continue
+		}
+
+		updated++
+	}
+
+	return updated, nil
+}
+
+// cancelAbandonedOrders cancels at most the given limit of
+// orders still in created status that are created before the
+// given time, along with their charge if there is any.
+//
+// It returns the number of cancelled orders.
+func (s *service) cancelAbandonedOrders(ctx context.Context, pgStoreClient postgresql.PGStoreClient, createdBefore time.Time, limit int) (int, error) {
+	// get the oldest created orders
+	orders, err := pgStoreClient.GetOrders(ctx, order.GetOrdersFilter{
+		Status:        order.StatusCreated,
+		CreateTimeTo:  createdBefore,
+		SortAscending: true,
+		Limit:         limit,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	cancelled := 0
+	for _, current := range orders {
+		if ctx.Err() != nil {
+			return cancelled, ctx.Err()
+		}
+
+		// the charge may or may not reach the payment gateway
+		_, err = s.gateway.Cancel(ctx, current.GatewayOrderID)
+		if err != nil && err != gateway.ErrTransactionNotFound {
+			log.Printf("[Order Service][ReconcilePendingOrders] Failed to cancel charge. orderID: %d. Err: %s\n", current.ID, err.Error())
+			continue
+		}
+
+		err = s.updateOrderStatus(ctx, current, order.StatusCancelled, order.SourceReconciler)
+		if err != nil {
+			log.Printf("[Order Service][ReconcilePendingOrders] Failed to cancel order. orderID: %d. Err: %s\n", current.ID, err.Error())
+			continue
+		}
+
+		cancelled++
+	}
+
+	return cancelled, nil
+}
+
+// checkOrderStatus returns the status of the given pending
+// order according to the payment gateway.
+//
+// Order whose charge has expired is considered cancelled,
+// even if the payment gateway still reports it as pending.
+func (s *service) checkOrderStatus(ctx context.Context, current order.Order) (order.Status, error) {
+	expired := s.timeNow().Sub(current.CreateTime) > gateway.ChargeExpiry
+
+	tx, err := s.gateway.CheckStatus(ctx, current.GatewayOrderID)
+	if err != nil {
+		// the charge never reach the payment gateway
+		if err == gateway.ErrTransactionNotFound && expired {
+			return order.StatusCancelled, nil
+		}
+		return order.StatusUnknown, err
+	}
+
+	if tx.Status == order.StatusPending && expired {
+		return order.StatusCancelled, nil
+	}
+
+	return tx.Status, nil
+}
@@ -3,23 +3,60 @@ package service
 import (
 	"time"
 
+	"github.com/go-redis/redis/v8"
+	"github.com/synapsis-test/internal/order/gateway"
 	"github.com/synapsis-test/internal/order/store/postgresql"
 	"github.com/synapsis-test/internal/product"
 )
 
+// gatewayOrderIDPrefix is the prefix of every order ID sent
+// to the payment gateway.
+const gatewayOrderIDPrefix = "SYNAPSIS"
+
+// compensationTimeout is the timeout to undo a failed
+// checkout.
+const compensationTimeout = 10 * time.Second
+
+// Followings are the limit of orders returned in GetOrders.
+const (
+	defaultGetOrdersLimit = 20
+	maxGetOrdersLimit     = 100
+)
+
+// Followings are the lifetime of an idempotency key record.
+const (
+	// idempotencyKeyTTL is how long the result of a request
+	// is replayed for the same idempotency key.
+	idempotencyKeyTTL = 24 * time.Hour
+
+	// idempotencyLockTTL is how long an idempotency key is
+	// held by a request in progress, so the key is released
+	// if the request never finishes.
+	idempotencyLockTTL = time.Minute
+
+	// idempotencyLockRefreshInterval is how often the lock
+	// of a request in progress is extended, so it is held
+	// until the request finishes however long it takes.
+	idempotencyLockRefreshInterval = idempotencyLockTTL / 3
+)
+
 // service implements user.Service.
 type service struct {
-	pgStore postgresql.PGStore
-	product product.Service
-	timeNow func() time.Time
+	pgStore     postgresql.PGStore
+	product     product.Service
+	gateway     gateway.PaymentGateway
+	redisClient *redis.Client
+	timeNow     func() time.Time
 }
 
 // New creates a new service.
-func New(pgStore postgresql.PGStore, product product.Service) (*service, error) {
+func New(pgStore postgresql.PGStore, product product.Service, paymentGateway gateway.PaymentGateway, redisClient *redis.Client) (*service, error) {
 	s := &service{
-		pgStore: pgStore,
-		product: product,
-		timeNow: time.Now,
+		pgStore:     pgStore,
+		product:     product,
+		gateway:     paymentGateway,
+		redisClient: redisClient,
+		timeNow:     time.Now,
 	}
 
 	return s, nil
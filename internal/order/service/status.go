@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+
+	"github.com/synapsis-test/internal/order"
+	"github.com/synapsis-test/internal/order/store/postgresql"
+)
+
+var (
+	// statusTransitions maps order status to the statuses it
+	// can legally move to.
+	//
+	// StatusUnknown denotes an order that is not created yet.
+	statusTransitions = map[order.Status][]order.Status{
+		order.StatusUnknown: {
+			order.StatusCreated,
+		},
+		order.StatusCreated: {
+			order.StatusPending,
+			order.StatusCancelled,
+		},
+		order.StatusPending: {
+			order.StatusSettlement,
+			order.StatusCancelled,
+		},
+		order.StatusSettlement: {
+			order.StatusRefunded,
+		},
+	}
+)
+
+func (s *service) GetOrderStatusHistory(ctx context.Context, id int64) ([]order.StatusHistory, error) {
+	// validate id
+	if id <= 0 {
+		return nil, order.ErrInvalidOrderID
+	}
+
+	// get pg store client
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return nil, err
+	}
+
+	// make sure the order exists
+	_, err = pgStoreClient.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgStoreClient.GetOrderStatusHistory(ctx, id)
+}
+
+// canTransitStatus returns whether an order with the given
+// current status can move to the given next status.
+func canTransitStatus(current, next order.Status) bool {
+	for _, status := range statusTransitions[current] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// updateOrderStatus updates the status of the given order
+// into the given status and records the transition.
+//
+// It returns order.ErrInvalidStatusTransition if the move
+// is not legal or the order status has been changed since
+// the given order is retrieved.
+func (s *service) updateOrderStatus(ctx context.Context, current order.Order, status order.Status, source order.Source) error {
+	if !canTransitStatus(current.Status, status) {
+		return order.ErrInvalidStatusTransition
+	}
+
+	// get pg store client using transaction
+	pgStoreClient, err := s.pgStore.NewClient(true)
+	if err != nil {
+		return err
+	}
+
+	settledItems, err := s.transitOrderStatus(ctx, pgStoreClient, current, status, source)
+	if err != nil {
+		pgStoreClient.Rollback()
+		return err
+	}
+
+	err = pgStoreClient.Commit()
+	if err != nil {
+		return err
+	}
+
+	if len(settledItems) > 0 {
+		s.invalidateProductsCache(settledItems)
+	}
+
+	return nil
+}
+
+// transitOrderStatus moves the given order into the given
+// status using the given pg store client, which should use
+// transaction. It returns the items whose product stock is
+// settled by the move, if any.
+//
+// It returns order.ErrInvalidStatusTransition if the move
+// is not legal or the order status has been changed since
+// the given order is retrieved.
+func (s *service) transitOrderStatus(ctx context.Context, pgStoreClient postgresql.PGStoreClient, current order.Order, status order.Status, source order.Source) ([]order.Item, error) {
+	if !canTransitStatus(current.Status, status) {
+		return nil, order.ErrInvalidStatusTransition
+	}
+
+	// update fields
+	oldStatus := current.Status
+	current.Status = status
+	current.UpdateTime = s.timeNow()
+
+	// update order status in pgstore
+	err := pgStoreClient.UpdateOrderStatus(ctx, current, oldStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	// settle the stock reserved by the order
+	settledItems, err := s.settleProductStocks(ctx, pgStoreClient, current.ID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	// record the transition in pgstore
+	err = pgStoreClient.CreateOrderStatusHistory(ctx, order.StatusHistory{
+		OrderID:    current.ID,
+		OldStatus:  oldStatus,
+		NewStatus:  status,
+		Source:     source,
+		CreateTime: current.UpdateTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return settledItems, nil
+}
+
+// settleProductStocks settles stock reserved by the order
+// with the given order ID according to its new status.
+//
+// Reservation is deducted from the stock once the order is
+// paid, or released back once the order is cancelled. It
+// returns the settled items, if any.
+func (s *service) settleProductStocks(ctx context.Context, pgStoreClient postgresql.PGStoreClient, orderID int64, status order.Status) ([]order.Item, error) {
+	if !changesProductStocks(status) {
+		return nil, nil
+	}
+
+	items, err := pgStoreClient.GetOrderItemsByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == order.StatusSettlement {
+		err = pgStoreClient.DeductProductStocks(ctx, items)
+	} else {
+		err = pgStoreClient.ReleaseProductStocks(ctx, items)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// changesProductStocks returns whether moving an order into
+// the given status changes stock of the ordered products.
+func changesProductStocks(status order.Status) bool {
+	return status == order.StatusSettlement || status == order.StatusCancelled
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/synapsis-test/internal/order"
+)
+
+func TestCanTransitStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		current order.Status
+		next    order.Status
+		want    bool
+	}{
+		{name: "create order", current: order.StatusUnknown, next: order.StatusCreated, want: true},
+		{name: "charge created order", current: order.StatusCreated, next: order.StatusPending, want: true},
+		{name: "cancel created order", current: order.StatusCreated, next: order.StatusCancelled, want: true},
+		{name: "settle pending order", current: order.StatusPending, next: order.StatusSettlement, want: true},
+		{name: "cancel pending order", current: order.StatusPending, next: order.StatusCancelled, want: true},
+		{name: "refund settled order", current: order.StatusSettlement, next: order.StatusRefunded, want: true},
+		{name: "create pending order", current: order.StatusUnknown, next: order.StatusPending, want: false},
+		{name: "settle created order", current: order.StatusCreated, next: order.StatusSettlement, want: false},
+		{name: "refund pending order", current: order.StatusPending, next: order.StatusRefunded, want: false},
+		{name: "cancel settled order", current: order.StatusSettlement, next: order.StatusCancelled, want: false},
+		{name: "settle cancelled order", current: order.StatusCancelled, next: order.StatusSettlement, want: false},
+		{name: "reopen cancelled order", current: order.StatusCancelled, next: order.StatusPending, want: false},
+		{name: "settle refunded order", current: order.StatusRefunded, next: order.StatusSettlement, want: false},
+		{name: "stay pending", current: order.StatusPending, next: order.StatusPending, want: false},
+		{name: "stay settled", current: order.StatusSettlement, next: order.StatusSettlement, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := canTransitStatus(tt.current, tt.next)
+			if got != tt.want {
+				t.Errorf("canTransitStatus(%s, %s) = %v, want %v", tt.current, tt.next, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangesProductStocks(t *testing.T) {
+	tests := []struct {
+		status order.Status
+		want   bool
+	}{
+		{status: order.StatusCreated, want: false},
+		{status: order.StatusPending, want: false},
+		{status: order.StatusSettlement, want: true},
+		{status: order.StatusCancelled, want: true},
+		{status: order.StatusRefunded, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status.String(), func(t *testing.T) {
+			got := changesProductStocks(tt.status)
+			if got != tt.want {
+				t.Errorf("changesProductStocks(%s) = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
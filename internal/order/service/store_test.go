@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/synapsis-test/internal/order"
+	"github.com/synapsis-test/internal/order/store/postgresql"
+	"github.com/synapsis-test/internal/product"
+)
+
+// fakeData denotes the data stored by fakeStore.
+type fakeData struct {
+	lastOrderID int64
+	orders      map[int64]order.Order
+	items       map[int64][]order.Item
+	histories   []order.StatusHistory
+	stocks      map[int64]int64 // available stock by product ID
+	reserved    map[int64]int64 // reserved stock by product ID
+	carts       map[int64][]int64
+}
+
+// clone returns a deep copy of the data.
+func (d *fakeData) clone() *fakeData {
+	c := &fakeData{
+		lastOrderID: d.lastOrderID,
+		orders:      make(map[int64]order.Order, len(d.orders)),
+		items:       make(map[int64][]order.Item, len(d.items)),
+		histories:   append([]order.StatusHistory(nil), d.histories...),
+		stocks:      make(map[int64]int64, len(d.stocks)),
+		reserved:    make(map[int64]int64, len(d.reserved)),
+		carts:       make(map[int64][]int64, len(d.carts)),
+	}
+	for k, v := range d.orders {
+		c.orders[k] = v
+	}
+	for k, v := range d.items {
+		c.items[k] = append([]order.Item(nil), v...)
+	}
+	for k, v := range d.stocks {
+		c.stocks[k] = v
+	}
+	for k, v := range d.reserved {
+		c.reserved[k] = v
+	}
+	for k, v := range d.carts {
+		c.carts[k] = append([]int64(nil), v...)
+	}
+	return c
+}
+
+// fakeStore implements postgresql.PGStore in memory. Changes
+// made in a transaction are only stored once it is committed.
+type fakeStore struct {
+	data *fakeData
+
+	// errDeleteProductCarts is returned from
+	// DeleteProductCarts if set.
+	errDeleteProductCarts error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		data: &fakeData{
+			orders:   make(map[int64]order.Order),
+			items:    make(map[int64][]order.Item),
+			stocks:   make(map[int64]int64),
+			reserved: make(map[int64]int64),
+			carts:    make(map[int64][]int64),
+		},
+	}
+}
+
+func (s *fakeStore) NewClient(useTx bool) (postgresql.PGStoreClient, error) {
+	if !useTx {
+		return &fakeStoreClient{store: s, data: s.data}, nil
+	}
+	return &fakeStoreClient{store: s, data: s.data.clone(), useTx: true}, nil
+}
+
+// fakeStoreClient implements postgresql.PGStoreClient.
+type fakeStoreClient struct {
+	store *fakeStore
+	data  *fakeData
+	useTx bool
+}
+
+func (sc *fakeStoreClient) Commit() error {
+	if sc.useTx {
+		sc.store.data = sc.data
+	}
+	return nil
+}
+
+func (sc *fakeStoreClient) Rollback() error {
+	return nil
+}
+
+func (sc *fakeStoreClient) CreateOrder(ctx context.Context, reqOrder order.Order) (int64, error) {
+	sc.data.lastOrderID++
+	reqOrder.ID = sc.data.lastOrderID
+	reqOrder.Items = nil
+	sc.data.orders[reqOrder.ID] = reqOrder
+	return reqOrder.ID, nil
+}
+
+func (sc *fakeStoreClient) CreateOrderItems(ctx context.Context, orderID int64, items []order.Item) error {
+	for _, item := range items {
+		item.OrderID = orderID
+		sc.data.items[orderID] = append(sc.data.items[orderID], item)
+	}
+	return nil
+}
+
+func (sc *fakeStoreClient) GetOrderByID(ctx context.Context, id int64) (order.Order, error) {
+	result, ok := sc.data.orders[id]
+	if !ok {
+		return order.Order{}, order.ErrDataNotFound
+	}
+	return result, nil
+}
+
+func (sc *fakeStoreClient) GetOrderByGatewayOrderID(ctx context.Context, gatewayOrderID string) (order.Order, error) {
+	for _, o := range sc.data.orders {
+		if o.GatewayOrderID == gatewayOrderID {
+			return o, nil
+		}
+	}
+	return order.Order{}, order.ErrDataNotFound
+}
+
+func (sc *fakeStoreClient) GetOrders(ctx context.Context, filter order.GetOrdersFilter) ([]order.Order, error) {
+	result := []order.Order{}
+	for _, o := range sc.data.orders {
+		if filter.UserID > 0 && o.UserID != filter.UserID {
+			continue
+		}
+		if filter.Status != order.StatusUnknown && o.Status != filter.Status {
+			continue
+		}
+		result = append(result, o)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func (sc *fakeStoreClient) GetOrdersToReconcile(ctx context.Context, status order.Status, createdBefore time.Time, limit int) ([]order.Order, error) {
+	return nil, nil
+}
+
+func (sc *fakeStoreClient) UpdateOrderReconcileTime(ctx context.Context, orderID int64, reconcileTime time.Time) error {
+	return nil
+}
+
+func (sc *fakeStoreClient) GetOrderItemsByOrderID(ctx context.Context, orderID int64) ([]order.Item, error) {
+	return append([]order.Item(nil), sc.data.items[orderID]...), nil
+}
+
+func (sc *fakeStoreClient) GetOrderItemsByOrderIDs(ctx context.Context, orderIDs []int64) ([]order.Item, error) {
+	result := []order.Item{}
+	for _, id := range orderIDs {
+		result = append(result, sc.data.items[id]...)
+	}
+	return result, nil
+}
+
+func (sc *fakeStoreClient) UpdateOrderStatus(ctx context.Context, reqOrder order.Order, currentStatus order.Status) error {
+	current, ok := sc.data.orders[reqOrder.ID]
+	if !ok || current.Status != currentStatus {
+		return order.ErrInvalidStatusTransition
+	}
+	current.Status = reqOrder.Status
+	current.UpdateTime = reqOrder.UpdateTime
+	sc.data.orders[reqOrder.ID] = current
+	return nil
+}
+
+func (sc *fakeStoreClient) CreateOrderStatusHistory(ctx context.Context, history order.StatusHistory) error {
+	history.ID = int64(len(sc.data.histories) + 1)
+	sc.data.histories = append(sc.data.histories, history)
+	return nil
+}
+
+func (sc *fakeStoreClient) GetOrderStatusHistory(ctx context.Context, orderID int64) ([]order.StatusHistory, error) {
+	result := []order.StatusHistory{}
+	for _, history := range sc.data.histories {
+		if history.OrderID == orderID {
+			result = append(result, history)
+		}
+	}
+	return result, nil
+}
+
+func (sc *fakeStoreClient) ReserveProductStocks(ctx context.Context, items []order.Item) error {
+	for _, item := range items {
+		if sc.data.stocks[item.ProductID] < item.Quantity {
+			return product.ErrOutOfStock
+		}
+		sc.data.stocks[item.ProductID] -= item.Quantity
+		sc.data.reserved[item.ProductID] += item.Quantity
+	}
+	return nil
+}
+
+func (sc *fakeStoreClient) ReleaseProductStocks(ctx context.Context, items []order.Item) error {
+	for _, item := range items {
+		sc.data.stocks[item.ProductID] += item.Quantity
+		sc.data.reserved[item.ProductID] -= item.Quantity
+	}
+	return nil
+}
+
+func (sc *fakeStoreClient) DeductProductStocks(ctx context.Context, items []order.Item) error {
+	for _, item := range items {
+		sc.data.reserved[item.ProductID] -= item.Quantity
+	}
+	return nil
+}
+
+func (sc *fakeStoreClient) DeleteProductCarts(ctx context.Context, userID int64, productIDs []int64) error {
+	if sc.store.errDeleteProductCarts != nil {
+		return sc.store.errDeleteProductCarts
+	}
+
+	deleted := make(map[int64]bool, len(productIDs))
+	for _, id := range productIDs {
+		deleted[id] = true
+	}
+
+	remaining := []int64{}
+	for _, id := range sc.data.carts[userID] {
+		if !deleted[id] {
+			remaining = append(remaining, id)
+		}
+	}
+	sc.data.carts[userID] = remaining
+	return nil
+}
+
+func (sc *fakeStoreClient) UpdateOrderPayment(ctx context.Context, reqOrder order.Order) error {
+	current, ok := sc.data.orders[reqOrder.ID]
+	if !ok {
+		return order.ErrDataNotFound
+	}
+	current.GatewayOrderID = reqOrder.GatewayOrderID
+	current.GatewayTransactionID = reqOrder.GatewayTransactionID
+	current.PaymentType = reqOrder.PaymentType
+	current.QRString = reqOrder.QRString
+	current.ResponseMidtrans = reqOrder.ResponseMidtrans
+	sc.data.orders[reqOrder.ID] = current
+	return nil
+}
+
+// fakeProductService implements the parts of product.Service
+// used by the order service.
+type fakeProductService struct {
+	product.Service
+	carts          map[int64][]product.ProductCart
+	invalidatedIDs []int64
+}
+
+func (s *fakeProductService) GetCartsByUserID(ctx context.Context, userID int64) ([]product.ProductCart, error) {
+	return s.carts[userID], nil
+}
+
+func (s *fakeProductService) InvalidateCachedProducts(ctx context.Context, ids []int64) error {
+	s.invalidatedIDs = append(s.invalidatedIDs, ids...)
+	return nil
+}
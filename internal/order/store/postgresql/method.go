@@ -4,17 +4,19 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/synapsis-test/internal/order"
+	"github.com/synapsis-test/internal/product"
 )
 
 func (sc *storeClient) CreateOrder(ctx context.Context, reqOrder order.Order) (int64, error) {
 	// construct arguments filled with fields for the query
 	argsKV := map[string]interface{}{
 		"user_id":           reqOrder.UserID,
-		"product_id":        reqOrder.ProductID,
-		"quantity":          reqOrder.Quantity,
 		"total_amount":      reqOrder.TotalAmount,
 		"status":            reqOrder.Status,
 		"response_midtrans": reqOrder.ResponseMidtrans,
@@ -42,6 +44,38 @@ func (sc *storeClient) CreateOrder(ctx context.Context, reqOrder order.Order) (i
 	return orderID, nil
 }
 
+func (sc *storeClient) CreateOrderItems(ctx context.Context, orderID int64, items []order.Item) error {
+	for _, item := range items {
+		// construct arguments filled with fields for the query
+		argsKV := map[string]interface{}{
+			"order_id":    orderID,
+			"product_id":  item.ProductID,
+			"price":       item.Price,
+			"quantity":    item.Quantity,
+			"create_time": item.CreateTime,
+		}
+
+		// prepare query
+		query, args, err := sqlx.Named(queryCreateOrderItem, argsKV)
+		if err != nil {
+			return err
+		}
+		query, args, err = sqlx.In(query, args...)
+		if err != nil {
+			return err
+		}
+		query = sc.q.Rebind(query)
+
+		// execute query
+		_, err = sc.q.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (sc *storeClient) GetOrderByID(ctx context.Context, id int64) (order.Order, error) {
 	query := fmt.Sprintf(queryGetOrder, "WHERE t.id = $1")
 	// query single row
@@ -56,3 +90,483 @@ func (sc *storeClient) GetOrderByID(ctx context.Context, id int64) (order.Order,
 
 	return odb.format(), nil
 }
+
+func (sc *storeClient) GetOrderByGatewayOrderID(ctx context.Context, gatewayOrderID string) (order.Order, error) {
+	query := fmt.Sprintf(queryGetOrder, "WHERE t.gateway_order_id = $1")
+	// query single row
+	var odb orderDB
+	err := sc.q.QueryRowx(query, gatewayOrderID).StructScan(&odb)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return order.Order{}, order.ErrDataNotFound
+		}
+		return order.Order{}, err
+	}
+
+	return odb.format(), nil
+}
+
+func (sc *storeClient) GetOrders(ctx context.Context, filter order.GetOrdersFilter) ([]order.Order, error) {
+	// define variables to custom query
+	argsKV := make(map[string]interface{})
+	addConditions := make([]string, 0)
+
+	if filter.UserID > 0 {
+		addConditions = append(addConditions, "t.user_id = :user_id")
+		argsKV["user_id"] = filter.UserID
+	}
+
+	if filter.Status != order.StatusUnknown {
+		addConditions = append(addConditions, "t.status = :status")
+		argsKV["status"] = filter.Status
+	}
+
+	if filter.ProductID > 0 {
+		addConditions = append(addConditions, "EXISTS (SELECT 1 FROM order_item oi WHERE oi.order_id = t.id AND oi.product_id = :product_id)")
+		argsKV["product_id"] = filter.ProductID
+	}
+
+	if !filter.CreateTimeFrom.IsZero() {
+		addConditions = append(addConditions, "t.create_time >= :create_time_from")
+		argsKV["create_time_from"] = filter.CreateTimeFrom
+	}
+
+	if !filter.CreateTimeTo.IsZero() {
+		addConditions = append(addConditions, "t.create_time < :create_time_to")
+		argsKV["create_time_to"] = filter.CreateTimeTo
+	}
+
+	// construct strings to custom query
+	addCondition := strings.Join(addConditions, " AND ")
+
+	// since the query does not contains "WHERE" yet, need
+	// to add it if needed
+	if len(addConditions) > 0 {
+		addCondition = fmt.Sprintf("WHERE %s", addCondition)
+	}
+
+	// sort by create time, order ID breaks the tie so
+	// pagination is stable
+	sortDirection := "DESC"
+	if filter.SortAscending {
+		sortDirection = "ASC"
+	}
+	addCondition = fmt.Sprintf("%s ORDER BY t.create_time %s, t.id %s", addCondition, sortDirection, sortDirection)
+
+	// paginate
+	if filter.Limit > 0 {
+		addCondition = fmt.Sprintf("%s LIMIT :limit", addCondition)
+		argsKV["limit"] = filter.Limit
+	}
+
+	if filter.Offset > 0 {
+		addCondition = fmt.Sprintf("%s OFFSET :offset", addCondition)
+		argsKV["offset"] = filter.Offset
+	}
+
+	// construct query
+	query := fmt.Sprintf(queryGetOrder, addCondition)
+
+	// prepare query
+	query, args, err := sqlx.Named(query, argsKV)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	query = sc.q.Rebind(query)
+
+	// query to database
+	rows, err := sc.q.Queryx(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// read orders
+	orders := make([]order.Order, 0)
+	for rows.Next() {
+		var row orderDB
+		err = rows.StructScan(&row)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, row.format())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (sc *storeClient) GetOrdersToReconcile(ctx context.Context, status order.Status, createdBefore time.Time, limit int) ([]order.Order, error) {
+	// orders never reconciled come first, then the ones
+	// reconciled the longest ago, so every order gets its turn
+	query := fmt.Sprintf(queryGetOrder, `
+		WHERE t.status = :status AND t.create_time < :create_time_to
+		ORDER BY t.reconcile_time ASC NULLS FIRST, t.id ASC
+		LIMIT :limit
+	`)
+	argsKV := map[string]interface{}{
+		"status":         status,
+		"create_time_to": createdBefore,
+		"limit":          limit,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(query, argsKV)
+	if err != nil {
+		return nil, err
+	}
+	query = sc.q.Rebind(query)
+
+	// query to database
+	rows, err := sc.q.Queryx(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// read orders
+	orders := make([]order.Order, 0)
+	for rows.Next() {
+		var row orderDB
+		err = rows.StructScan(&row)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, row.format())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (sc *storeClient) UpdateOrderReconcileTime(ctx context.Context, orderID int64, reconcileTime time.Time) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"id":             orderID,
+		"reconcile_time": reconcileTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryUpdateOrderReconcileTime, argsKV)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	_, err = sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (sc *storeClient) GetOrderItemsByOrderID(ctx context.Context, orderID int64) ([]order.Item, error) {
+	query := fmt.Sprintf(queryGetOrderItem, "WHERE oi.order_id = $1 ORDER BY oi.id")
+
+	// query to database
+	rows, err := sc.q.Queryx(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// read order items
+	items := make([]order.Item, 0)
+	for rows.Next() {
+		var row orderItemDB
+		err = rows.StructScan(&row)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, row.format())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (sc *storeClient) GetOrderItemsByOrderIDs(ctx context.Context, orderIDs []int64) ([]order.Item, error) {
+	// nothing to query
+	if len(orderIDs) == 0 {
+		return []order.Item{}, nil
+	}
+
+	// construct query
+	query := fmt.Sprintf(queryGetOrderItem, "WHERE oi.order_id IN (:order_ids) ORDER BY oi.id")
+
+	// prepare query
+	query, args, err := sqlx.Named(query, map[string]interface{}{
+		"order_ids": orderIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	query = sc.q.Rebind(query)
+
+	// query to database
+	rows, err := sc.q.Queryx(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// read order items
+	items := make([]order.Item, 0)
+	for rows.Next() {
+		var row orderItemDB
+		err = rows.StructScan(&row)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, row.format())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (sc *storeClient) UpdateOrderStatus(ctx context.Context, reqOrder order.Order, currentStatus order.Status) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"id":             reqOrder.ID,
+		"status":         reqOrder.Status,
+		"current_status": currentStatus,
+		"update_time":    reqOrder.UpdateTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryUpdateOrderStatus, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	result, err := sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	// make sure the order status is not changed concurrently
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return order.ErrInvalidStatusTransition
+	}
+
+	return nil
+}
+
+func (sc *storeClient) UpdateOrderPayment(ctx context.Context, reqOrder order.Order) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"id":                     reqOrder.ID,
+		"gateway_order_id":       reqOrder.GatewayOrderID,
+		"gateway_transaction_id": reqOrder.GatewayTransactionID,
+		"payment_type":           reqOrder.PaymentType,
+		"qr_string":              reqOrder.QRString,
+		"response_midtrans":      reqOrder.ResponseMidtrans,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryUpdateOrderPayment, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	_, err = sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (sc *storeClient) CreateOrderStatusHistory(ctx context.Context, history order.StatusHistory) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"order_id":    history.OrderID,
+		"old_status":  history.OldStatus,
+		"new_status":  history.NewStatus,
+		"source":      history.Source,
+		"create_time": history.CreateTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryCreateOrderStatusHistory, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	_, err = sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (sc *storeClient) GetOrderStatusHistory(ctx context.Context, orderID int64) ([]order.StatusHistory, error) {
+	query := fmt.Sprintf(queryGetOrderStatusHistory, "WHERE osh.order_id = $1 ORDER BY osh.create_time ASC, osh.id ASC")
+
+	// query to database
+	rows, err := sc.q.Queryx(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// read order status history
+	history := make([]order.StatusHistory, 0)
+	for rows.Next() {
+		var row orderStatusHistoryDB
+		err = rows.StructScan(&row)
+		if err != nil {
+			return nil, err
+		}
+
+		history = append(history, row.format())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func (sc *storeClient) ReserveProductStocks(ctx context.Context, items []order.Item) error {
+	return sc.updateProductStocks(ctx, queryReserveProductStock, items, product.ErrOutOfStock)
+}
+
+func (sc *storeClient) ReleaseProductStocks(ctx context.Context, items []order.Item) error {
+	return sc.updateProductStocks(ctx, queryReleaseProductStock, items, order.ErrInvalidProductID)
+}
+
+func (sc *storeClient) DeductProductStocks(ctx context.Context, items []order.Item) error {
+	return sc.updateProductStocks(ctx, queryDeductProductStock, items, order.ErrInvalidProductID)
+}
+
+// updateProductStocks executes the given stock query for
+// every product in the given items, and returns the given
+// error if any product is not updated.
+func (sc *storeClient) updateProductStocks(ctx context.Context, queryStock string, items []order.Item, errNotUpdated error) error {
+	// lock products always in the same order to prevent
+	// deadlock between concurrent transactions
+	sorted := make([]order.Item, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ProductID < sorted[j].ProductID
+	})
+
+	for _, item := range sorted {
+		// construct arguments filled with fields for the query
+		argsKV := map[string]interface{}{
+			"product_id": item.ProductID,
+			"quantity":   item.Quantity,
+		}
+
+		// prepare query
+		query, args, err := sqlx.Named(queryStock, argsKV)
+		if err != nil {
+			return err
+		}
+		query, args, err = sqlx.In(query, args...)
+		if err != nil {
+			return err
+		}
+		query = sc.q.Rebind(query)
+
+		// execute query
+		result, err := sc.q.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return errNotUpdated
+		}
+	}
+
+	return nil
+}
+
+func (sc *storeClient) DeleteProductCarts(ctx context.Context, userID int64, productIDs []int64) error {
+	// nothing to delete
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"user_id":     userID,
+		"product_ids": productIDs,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryDeleteProductCarts, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	_, err = sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package postgresql
 
 import (
+	"database/sql"
 	"errors"
 	"time"
 
@@ -66,36 +67,36 @@ func (sc *storeClient) Rollback() error {
 
 // orderDB denotes a data in the store.
 type orderDB struct {
-	ID               int64        `db:"id"`
-	UserID           int64        `db:"user_id"`
-	UserName         string       `db:"user_name"`
-	UserEmail        string       `db:"user_email"`
-	ProductID        int64        `db:"product_id"`
-	ProductName      string       `db:"product_name"`
-	ProductPrice     int64        `db:"product_price"`
-	Quantity         int64        `db:"quantity"`
-	TotalAmount      int64        `db:"total_amount"`
-	Status           order.Status `db:"status"`
-	ResponseMidtrans string       `db:"response_midtrans"`
-	CreateTime       time.Time    `db:"create_time"`
-	UpdateTime       *time.Time   `db:"update_time"`
+	ID                   int64          `db:"id"`
+	UserID               int64          `db:"user_id"`
+	UserName             string         `db:"user_name"`
+	UserEmail            string         `db:"user_email"`
+	TotalAmount          int64          `db:"total_amount"`
+	Status               order.Status   `db:"status"`
+	GatewayOrderID       sql.NullString `db:"gateway_order_id"`
+	GatewayTransactionID sql.NullString `db:"gateway_transaction_id"`
+	PaymentType          sql.NullString `db:"payment_type"`
+	QRString             sql.NullString `db:"qr_string"`
+	ResponseMidtrans     string         `db:"response_midtrans"`
+	CreateTime           time.Time      `db:"create_time"`
+	UpdateTime           *time.Time     `db:"update_time"`
 }
 
 // format formats database struct into domain struct.
 func (odb *orderDB) format() order.Order {
 	o := order.Order{
-		ID:               odb.ID,
-		UserID:           odb.UserID,
-		UserName:         odb.UserName,
-		UserEmail:        odb.UserEmail,
-		ProductID:        odb.ProductID,
-		ProductName:      odb.ProductName,
-		ProductPrice:     odb.ProductPrice,
-		Quantity:         odb.Quantity,
-		TotalAmount:      odb.TotalAmount,
-		Status:           odb.Status,
-		ResponseMidtrans: odb.ResponseMidtrans,
-		CreateTime:       odb.CreateTime,
+		ID:                   odb.ID,
+		UserID:               odb.UserID,
+		UserName:             odb.UserName,
+		UserEmail:            odb.UserEmail,
+		TotalAmount:          odb.TotalAmount,
+		Status:               odb.Status,
+		GatewayOrderID:       odb.GatewayOrderID.String,
+		GatewayTransactionID: odb.GatewayTransactionID.String,
+		PaymentType:          odb.PaymentType.String,
+		QRString:             odb.QRString.String,
+		ResponseMidtrans:     odb.ResponseMidtrans,
+		CreateTime:           odb.CreateTime,
 	}
 
 	if odb.UpdateTime != nil {
@@ -104,3 +105,50 @@ func (odb *orderDB) format() order.Order {
 
 	return o
 }
+
+// orderItemDB denotes an order item data in the store.
+type orderItemDB struct {
+	ID          int64     `db:"id"`
+	OrderID     int64     `db:"order_id"`
+	ProductID   int64     `db:"product_id"`
+	ProductName string    `db:"product_name"`
+	Price       int64     `db:"price"`
+	Quantity    int64     `db:"quantity"`
+	CreateTime  time.Time `db:"create_time"`
+}
+
+// format formats database struct into domain struct.
+func (oidb *orderItemDB) format() order.Item {
+	return order.Item{
+		ID:          oidb.ID,
+		OrderID:     oidb.OrderID,
+		ProductID:   oidb.ProductID,
+		ProductName: oidb.ProductName,
+		Price:       oidb.Price,
+		Quantity:    oidb.Quantity,
+		CreateTime:  oidb.CreateTime,
+	}
+}
+
+// orderStatusHistoryDB denotes an order status history data
+// in the store.
+type orderStatusHistoryDB struct {
+	ID         int64        `db:"id"`
+	OrderID    int64        `db:"order_id"`
+	OldStatus  order.Status `db:"old_status"`
+	NewStatus  order.Status `db:"new_status"`
+	Source     order.Source `db:"source"`
+	CreateTime time.Time    `db:"create_time"`
+}
+
+// format formats database struct into domain struct.
+func (oshdb *orderStatusHistoryDB) format() order.StatusHistory {
+	return order.StatusHistory{
+		ID:         oshdb.ID,
+		OrderID:    oshdb.OrderID,
+		OldStatus:  oshdb.OldStatus,
+		NewStatus:  oshdb.NewStatus,
+		Source:     oshdb.Source,
+		CreateTime: oshdb.CreateTime,
+	}
+}
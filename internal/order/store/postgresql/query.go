@@ -5,17 +5,13 @@ const queryCreateOrder = `
 		transaction
 	(
 		user_id,
-		product_id,
 		total_amount,
-		quantity,
 		status,
 		response_midtrans,
 		create_time
 	) VALUES (
 		:user_id,
-		:product_id,
 		:total_amount,
-		:quantity,
 		:status,
 		:response_midtrans,
 		:create_time
@@ -23,6 +19,23 @@ const queryCreateOrder = `
 		id
 `
 
+const queryCreateOrderItem = `
+	INSERT INTO
+		order_item
+	(
+		order_id,
+		product_id,
+		price,
+		quantity,
+		create_time
+	) VALUES (
+		:order_id,
+		:product_id,
+		:price,
+		:quantity,
+		:create_time
+	)
+`
 
 const queryGetOrder = `
 	SELECT
@@ -30,30 +43,146 @@ const queryGetOrder = `
 		t.user_id,
 		ui.name AS user_name,
 		ui.email AS user_email,
-		t.product_id,
-		p.name AS product_name,
-		p.price AS product_price,
-		t.quantity,
 		t.total_amount,
 		t.status,
+		t.gateway_order_id,
+		t.gateway_transaction_id,
+		t.payment_type,
+		t.qr_string,
 		t.response_midtrans,
 		t.create_time,
 		t.update_time
 	FROM
 		transaction t
 	LEFT JOIN
-		product_cart pc
+		user_info ui
 	ON
-		t.user_id = pc.user_id
-	AND
-		t.product_id = pc.product_id
+		ui.id = t.user_id
+	%s
+`
+
+const queryGetOrderItem = `
+	SELECT
+		oi.id,
+		oi.order_id,
+		oi.product_id,
+		p.name AS product_name,
+		oi.price,
+		oi.quantity,
+		oi.create_time
+	FROM
+		order_item oi
 	LEFT JOIN
 		product p
 	ON
-		p.id = t.product_id
-	LEFT JOIN
-		user_info ui
-	ON
-		ui.id = t.user_id
+		p.id = oi.product_id
 	%s
 `
+
+const queryUpdateOrderStatus = `
+	UPDATE
+		transaction
+	SET
+		status = :status,
+		update_time = :update_time
+	WHERE
+		id = :id
+	AND
+		status = :current_status
+`
+
+const queryUpdateOrderPayment = `
+	UPDATE
+		transaction
+	SET
+		gateway_order_id = :gateway_order_id,
+		gateway_transaction_id = :gateway_transaction_id,
+		payment_type = :payment_type,
+		qr_string = :qr_string,
+		response_midtrans = :response_midtrans
+	WHERE
+		id = :id
+`
+
+// queryUpdateOrderReconcileTime marks when the order is last
+// checked by the reconciler.
+const queryUpdateOrderReconcileTime = `
+	UPDATE
+		transaction
+	SET
+		reconcile_time = :reconcile_time
+	WHERE
+		id = :id
+`
+
+const queryCreateOrderStatusHistory = `
+	INSERT INTO
+		order_status_history
+	(
+		order_id,
+		old_status,
+		new_status,
+		source,
+		create_time
+	) VALUES (
+		:order_id,
+		:old_status,
+		:new_status,
+		:source,
+		:create_time
+	)
+`
+
+const queryGetOrderStatusHistory = `
+	SELECT
+		osh.id,
+		osh.order_id,
+		osh.old_status,
+		osh.new_status,
+		osh.source,
+		osh.create_time
+	FROM
+		order_status_history osh
+	%s
+`
+
+const queryReserveProductStock = `
+	UPDATE
+		product
+	SET
+		reserved_stock = reserved_stock + :quantity
+	WHERE
+		id = :product_id
+	AND
+		stock - reserved_stock >= :quantity
+	AND
+		deleted_at IS NULL
+`
+
+const queryReleaseProductStock = `
+	UPDATE
+		product
+	SET
+		reserved_stock = reserved_stock - :quantity
+	WHERE
+		id = :product_id
+`
+
+const queryDeductProductStock = `
+	UPDATE
+		product
+	SET
+		stock = stock - :quantity,
+		reserved_stock = reserved_stock - :quantity
+	WHERE
+		id = :product_id
+`
+
+const queryDeleteProductCarts = `
+	DELETE FROM
+		product_cart
+	WHERE
+		user_id = :user_id
+	AND
+		product_id IN (:product_ids)
+`
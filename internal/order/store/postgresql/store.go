@@ -2,6 +2,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/synapsis-test/internal/order"
 )
@@ -21,6 +22,85 @@ type PGStoreClient interface {
 	// created order ID.
 	CreateOrder(ctx context.Context, order order.Order) (int64, error)
 
+	// CreateOrderItems creates the given items for the
+	// order with the given order ID.
+	CreateOrderItems(ctx context.Context, orderID int64, items []order.Item) error
+
 	// GetOrderByID returns a order with the given order ID.
+	//
+	// The returned order does not contain its items, use
+	// GetOrderItemsByOrderID to get them.
 	GetOrderByID(ctx context.Context, id int64) (order.Order, error)
+
+	// GetOrderByGatewayOrderID returns a order with the given
+	// order ID known by the payment gateway.
+	//
+	// The returned order does not contain its items, use
+	// GetOrderItemsByOrderID to get them.
+	GetOrderByGatewayOrderID(ctx context.Context, gatewayOrderID string) (order.Order, error)
+
+	// GetOrders returns list of orders that satisfy the given
+	// filter.
+	//
+	// The returned orders do not contain their items, use
+	// GetOrderItemsByOrderIDs to get them.
+	GetOrders(ctx context.Context, filter order.GetOrdersFilter) ([]order.Order, error)
+
+	// GetOrdersToReconcile returns at most the given limit of
+	// orders with the given status created before the given
+	// time, the ones never or least recently reconciled first.
+	//
+	// The returned orders do not contain their items.
+	GetOrdersToReconcile(ctx context.Context, status order.Status, createdBefore time.Time, limit int) ([]order.Order, error)
+
+	// UpdateOrderReconcileTime marks the order with the given
+	// order ID as reconciled at the given time.
+	UpdateOrderReconcileTime(ctx context.Context, orderID int64, reconcileTime time.Time) error
+
+	// GetOrderItemsByOrderID returns all items of the order
+	// with the given order ID.
+	GetOrderItemsByOrderID(ctx context.Context, orderID int64) ([]order.Item, error)
+
+	// GetOrderItemsByOrderIDs returns all items of the orders
+	// with the given order IDs.
+	GetOrderItemsByOrderIDs(ctx context.Context, orderIDs []int64) ([]order.Item, error)
+
+	// UpdateOrderStatus updates the status and update time
+	// of the given order, only if the order status is still
+	// the given current status.
+	//
+	// It returns order.ErrInvalidStatusTransition if the
+	// order status has been changed.
+	UpdateOrderStatus(ctx context.Context, order order.Order, currentStatus order.Status) error
+
+	// CreateOrderStatusHistory creates the given order status
+	// history.
+	CreateOrderStatusHistory(ctx context.Context, history order.StatusHistory) error
+
+	// GetOrderStatusHistory returns every status history of
+	// the order with the given order ID, oldest first.
+	GetOrderStatusHistory(ctx context.Context, orderID int64) ([]order.StatusHistory, error)
+
+	// ReserveProductStocks reserves stock of every product in
+	// the given items, so it can not be ordered by others.
+	//
+	// It returns product.ErrOutOfStock if the available stock
+	// of any product is not enough.
+	ReserveProductStocks(ctx context.Context, items []order.Item) error
+
+	// ReleaseProductStocks releases stock reservation of every
+	// product in the given items.
+	ReleaseProductStocks(ctx context.Context, items []order.Item) error
+
+	// DeductProductStocks turns stock reservation of every
+	// product in the given items into actual stock deduction.
+	DeductProductStocks(ctx context.Context, items []order.Item) error
+
+	// DeleteProductCarts deletes the given products from the
+	// cart of the user with the given user ID.
+	DeleteProductCarts(ctx context.Context, userID int64, productIDs []int64) error
+
+	// UpdateOrderPayment updates the payment gateway data
+	// of the given order.
+	UpdateOrderPayment(ctx context.Context, order order.Order) error
 }
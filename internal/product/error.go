@@ -19,4 +19,36 @@ var (
 	// ErrInvalidQuantity is returned when the given quantity is
 	// invalid.
 	ErrInvalidQuantity = errors.New("invalid quantity")
+
+	// ErrOutOfStock is returned when the available stock of
+	// a product is less than the requested quantity.
+	ErrOutOfStock = errors.New("out of stock")
+
+	// ErrInvalidName is returned when the given product name
+	// is invalid.
+	ErrInvalidName = errors.New("invalid name")
+
+	// ErrInvalidPrice is returned when the given price is
+	// invalid.
+	ErrInvalidPrice = errors.New("invalid price")
+
+	// ErrInvalidStock is returned when the given stock is
+	// invalid.
+	ErrInvalidStock = errors.New("invalid stock")
+
+	// ErrInvalidCategoryID is returned when the given category
+	// ID is invalid or the category does not exist.
+	ErrInvalidCategoryID = errors.New("invalid category id")
+
+	// ErrInvalidQuery is returned when the given search query
+	// is invalid.
+	ErrInvalidQuery = errors.New("invalid query")
+
+	// ErrInvalidLimit is returned when the given limit is
+	// invalid.
+	ErrInvalidLimit = errors.New("invalid limit")
+
+	// ErrInvalidOffset is returned when the given offset is
+	// invalid.
+	ErrInvalidOffset = errors.New("invalid offset")
 )
@@ -40,13 +40,41 @@ var (
 	// invalid.
 	errInvalidQuantity = errors.New("INVALID_QUANTITY")
 
-	// errInvalidToken is returned when the given token is
+	// errOutOfStock is returned when the available stock of
+	// a product is less than the requested quantity.
+	errOutOfStock = errors.New("OUT_OF_STOCK")
+
+	// errInvalidName is returned when the given product name
+	// is invalid.
+	errInvalidName = errors.New("INVALID_NAME")
+
+	// errInvalidPrice is returned when the given price is
+	// invalid.
+	errInvalidPrice = errors.New("INVALID_PRICE")
+
+	// errInvalidStock is returned when the given stock is
+	// invalid.
+	errInvalidStock = errors.New("INVALID_STOCK")
+
+	// errInvalidCategoryID is returned when the given category
+	// ID is invalid or the category does not exist.
+	errInvalidCategoryID = errors.New("INVALID_CATEGORY_ID")
+
+	// errInvalidQuery is returned when the given search query
+	// is invalid.
+	errInvalidQuery = errors.New("INVALID_QUERY")
+
+	// errInvalidLimit is returned when the given limit is
+	// invalid.
+	errInvalidLimit = errors.New("INVALID_LIMIT")
+
+	// errInvalidOffset is returned when the given offset is
 	// invalid.
-	errInvalidToken = errors.New("INVALID_TOKEN")
+	errInvalidOffset = errors.New("INVALID_OFFSET")
 
-	// errUnauthorizedAccess is returned when the request
-	// is unaothorized.
-	errUnauthorizedAccess = errors.New("UNAUTHORIZED_ACCESS")
+	// errForbiddenAccess is returned when the request is
+	// authenticated but not allowed.
+	errForbiddenAccess = errors.New("FORBIDDEN_ACCESS")
 )
 
 var (
@@ -57,9 +85,17 @@ var (
 	// and the handler should just return `errInternal` as the
 	// error instead
 	mapHTTPError = map[error]error{
-		product.ErrDataNotFound:     errDataNotFound,
-		product.ErrInvalidProductID: errInvalidProductID,
-		product.ErrInvalidUserID:    errInvalidUserID,
-		product.ErrInvalidQuantity:    errInvalidQuantity,
+		product.ErrDataNotFound:      errDataNotFound,
+		product.ErrInvalidProductID:  errInvalidProductID,
+		product.ErrInvalidUserID:     errInvalidUserID,
+		product.ErrInvalidQuantity:   errInvalidQuantity,
+		product.ErrOutOfStock:        errOutOfStock,
+		product.ErrInvalidName:       errInvalidName,
+		product.ErrInvalidPrice:      errInvalidPrice,
+		product.ErrInvalidStock:      errInvalidStock,
+		product.ErrInvalidCategoryID: errInvalidCategoryID,
+		product.ErrInvalidQuery:      errInvalidQuery,
+		product.ErrInvalidLimit:      errInvalidLimit,
+		product.ErrInvalidOffset:     errInvalidOffset,
 	}
 )
@@ -11,12 +11,41 @@ func formatProduct(p product.Product) (productHTTP, error) {
 		ID:           &p.ID,
 		Name:         &p.Name,
 		Price:        &p.Price,
+		Stock:        &p.Stock,
 		Description:  &p.Description,
 		CategoryID:   &p.CategoryID,
 		CategoryName: &p.CategoryName,
 	}, nil
 }
 
+// parseProductFromRequest returns the given current product
+// with the fields given in the HTTP request object applied.
+func parseProductFromRequest(ph productHTTP, current product.Product) product.Product {
+	result := current
+
+	if ph.Name != nil {
+		result.Name = *ph.Name
+	}
+
+	if ph.Description != nil {
+		result.Description = *ph.Description
+	}
+
+	if ph.Price != nil {
+		result.Price = *ph.Price
+	}
+
+	if ph.Stock != nil {
+		result.Stock = *ph.Stock
+	}
+
+	if ph.CategoryID != nil {
+		result.CategoryID = *ph.CategoryID
+	}
+
+	return result
+}
+
 // formatProductCarrt formats the given product cart
 // into the respective HTTP-format object.
 func formatProductCart(pc product.ProductCart) (cartHTTP, error) {
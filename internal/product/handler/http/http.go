@@ -6,7 +6,6 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/synapsis-test/internal/product"
-	"github.com/synapsis-test/internal/user"
 )
 
 var (
@@ -17,7 +16,6 @@ var (
 type Handler struct {
 	handlers map[string]*handler
 	product  product.Service
-	client   user.Service
 }
 
 // handler is the HTTP handler wrapper.
@@ -30,6 +28,10 @@ type handler struct {
 type HandlerIdentity struct {
 	Name string
 	URL  string
+
+	// Public serves the handler without authentication,
+	// otherwise only authenticated requests are served.
+	Public bool
 }
 
 // Followings are the known HTTP handler identities
@@ -45,30 +47,43 @@ var (
 	// with a product.
 	HandlerProduct = HandlerIdentity{
 		Name: "product",
-		URL:  "/v1/products/{id}",
+		URL:  "/v1/products/{id:[0-9]+}",
+	}
+
+	// HandlerProductsSuggest denotes HTTP handler to suggest
+	// product names for a search query.
+	HandlerProductsSuggest = HandlerIdentity{
+		Name: "products-suggest",
+		URL:  "/v1/products/suggest",
 	}
 
 	// HandlerProductCart denotes HTTP handler to interact
 	// with a product cart.
 	HandlerProductCart = HandlerIdentity{
 		Name: "product-cart",
-		URL:  "/v1/products/{id}/carts",
+		URL:  "/v1/products/{id:[0-9]+}/carts",
 	}
 
 	// HandlerProductsCart denotes HTTP handler to interact
 	// with products cart.
 	HandlerProductsCart = HandlerIdentity{
 		Name: "products-cart",
-		URL:  "/v1/products/carts/{id}",
+		URL:  "/v1/products/carts/{id:[0-9]+}",
+	}
+
+	// HandlerMyCarts denotes HTTP handler to interact with
+	// the cart of the caller.
+	HandlerMyCarts = HandlerIdentity{
+		Name: "my-carts",
+		URL:  "/v1/products/carts/me",
 	}
 )
 
 // New creates a new Handler.
-func New(product product.Service, client user.Service, identities []HandlerIdentity) (*Handler, error) {
+func New(product product.Service, identities []HandlerIdentity) (*Handler, error) {
 	h := &Handler{
 		handlers: make(map[string]*handler),
 		product:  product,
-		client:   client,
 	}
 
 	// apply identity
@@ -100,22 +115,27 @@ func (h *Handler) createHTTPHandler(configName string) (http.Handler, error) {
 	case HandlerProducts.Name:
 		httpHandler = &productsHandler{
 			product: h.product,
-			client:  h.client,
 		}
 	case HandlerProduct.Name:
 		httpHandler = &productHandler{
 			product: h.product,
-			client:  h.client,
+		}
+	case HandlerProductsSuggest.Name:
+		httpHandler = &productsSuggestHandler{
+			product: h.product,
 		}
 	case HandlerProductsCart.Name:
 		httpHandler = &productsCartHandler{
 			product: h.product,
-			client:  h.client,
+		}
+	case HandlerMyCarts.Name:
+		httpHandler = &productsCartHandler{
+			product: h.product,
+			self:    true,
 		}
 	case HandlerProductCart.Name:
 		httpHandler = &productCartHandler{
 			product: h.product,
-			client:  h.client,
 		}
 	default:
 		return httpHandler, errUnknownConfig
@@ -123,10 +143,15 @@ func (h *Handler) createHTTPHandler(configName string) (http.Handler, error) {
 	return httpHandler, nil
 }
 
-// Start starts all HTTP handlers.
-func (h *Handler) Start(multiplexer *mux.Router) error {
+// Start starts all HTTP handlers. Handlers that are not
+// public are wrapped with the given authenticate middleware.
+func (h *Handler) Start(multiplexer *mux.Router, authenticate mux.MiddlewareFunc) error {
 	for _, handler := range h.handlers {
-		multiplexer.Handle(handler.identity.URL, handler.h)
+		if handler.identity.Public {
+			multiplexer.Handle(handler.identity.URL, handler.h)
+			continue
+		}
+		multiplexer.Handle(handler.identity.URL, authenticate(handler.h))
 	}
 	return nil
 }
@@ -135,6 +160,7 @@ type productHTTP struct {
 	ID           *int64  `json:"id"`
 	Name         *string `json:"name"`
 	Price        *int64  `json:"price"`
+	Stock        *int64  `json:"stock"`
 	Description  *string `json:"description"`
 	CategoryID   *int64  `json:"category_id"`
 	CategoryName *string `json:"category_name"`
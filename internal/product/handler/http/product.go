@@ -3,12 +3,15 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/synapsis-test/global/cache"
 	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
 	"github.com/synapsis-test/internal/product"
 	"github.com/gorilla/mux"
 	"github.com/synapsis-test/internal/user"
@@ -16,7 +19,6 @@ import (
 
 type productHandler struct {
 	product product.Service
-	client  user.Service
 }
 
 func (h *productHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -32,6 +34,10 @@ func (h *productHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		h.handleGetProductByID(w, r, productID)
+	case http.MethodPatch:
+		h.handleUpdateProduct(w, r, productID)
+	case http.MethodDelete:
+		h.handleDeleteProduct(w, r, productID)
 	default:
 		helper.WriteErrorResponse(w, http.StatusMethodNotAllowed, []string{errMethodNotAllowed.Error()})
 	}
@@ -65,25 +71,125 @@ func (h *productHandler) handleGetProductByID(w http.ResponseWriter, r *http.Req
 	errChan := make(chan error, 1)
 
 	go func() {
-		// get token from header
-		token, err := helper.GetBearerTokenFromHeader(r)
+		// check access of the authenticated user
+		_, err := auth.Authorize(ctx, user.RequireRole(user.RoleCustomer, user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		res, err := h.product.GetProductByID(ctx, productID)
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[Product HTTP][handleGetProductByID] Internal error from GetProductByID. productID: %d. Err: %s\n", productID, err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- res
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case res := <-resChan:
+		// format product
+		var p productHTTP
+		p, err = formatProduct(res)
+		if err != nil {
+			return
+		}
+		// construct response data
+		resBody, err = json.Marshal(helper.ResponseEnvelope{
+			Data: p,
+		})
+	}
+}
+
+func (h *productHandler) handleUpdateProduct(w http.ResponseWriter, r *http.Request, productID int64) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 3000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[Product HTTP][handleUpdateProduct] Failed to update product. productID: %d, Err: %s\n", productID, err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan product.Product, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// read body
+		body, err := ioutil.ReadAll(r.Body)
 		if err != nil {
 			statusCode = http.StatusBadRequest
-			errChan <- errInvalidToken
+			errChan <- errBadRequest
 			return
 		}
 
-		// check access token
-		err = checkAccessToken(ctx, h.client, token, "handleGetProductByID")
+		// unmarshall body
+		request := productHTTP{}
+		err = json.Unmarshal(body, &request)
 		if err != nil {
-			statusCode = http.StatusUnauthorized
-			errChan <- err
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
 			return
 		}
 
-		// TODO: add authorization flow with roles
+		// check access of the authenticated user, only admin
+		// can change the catalogue
+		_, err = auth.Authorize(ctx, user.RequireRole(user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// only the given fields are updated, the others keep
+		// their current values, which are read from store as
+		// the cached ones may be stale. The stock is only
+		// updated if given, since orders keep changing it.
+		current, err := h.product.GetProductByID(cache.WithBypass(ctx), productID)
+		if err == nil {
+			err = h.product.UpdateProduct(ctx, parseProductFromRequest(request, current), request.Stock != nil)
+		}
+
+		// get the updated product, category name may change
+		var res product.Product
+		if err == nil {
+			res, err = h.product.GetProductByID(cache.WithBypass(ctx), productID)
+		}
 
-		res, err := h.product.GetProductByID(ctx, productID)
 		if err != nil {
 			// determine error and status code, by default its internal error
 			parsedErr := errInternalServer
@@ -95,7 +201,7 @@ func (h *productHandler) handleGetProductByID(w http.ResponseWriter, r *http.Req
 
 			// log the actual error if its internal error
 			if statusCode == http.StatusInternalServerError {
-				log.Printf("[Product HTTP][handleGetProductByID] Internal error from GetProductByID. productID: %d. Err: %s\n", productID, err.Error())
+				log.Printf("[Product HTTP][handleUpdateProduct] Internal error from UpdateProduct. productID: %d. Err: %s\n", productID, err.Error())
 			}
 
 			errChan <- parsedErr
@@ -120,7 +226,80 @@ func (h *productHandler) handleGetProductByID(w http.ResponseWriter, r *http.Req
 		}
 		// construct response data
 		resBody, err = json.Marshal(helper.ResponseEnvelope{
-			Data: p,
+			Status: "Success",
+			Data:   p,
+		})
+	}
+}
+
+func (h *productHandler) handleDeleteProduct(w http.ResponseWriter, r *http.Request, productID int64) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 3000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[Product HTTP][handleDeleteProduct] Failed to delete product. productID: %d, Err: %s\n", productID, err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan struct{}, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// check access of the authenticated user, only admin
+		// can change the catalogue
+		_, err := auth.Authorize(ctx, user.RequireRole(user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		err = h.product.DeleteProduct(ctx, productID)
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[Product HTTP][handleDeleteProduct] Internal error from DeleteProduct. productID: %d. Err: %s\n", productID, err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- struct{}{}
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case <-resChan:
+		resBody, err = json.Marshal(helper.ResponseEnvelope{
+			Status: "Success",
 		})
 	}
 }
@@ -11,13 +11,13 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
 	"github.com/synapsis-test/internal/product"
 	"github.com/synapsis-test/internal/user"
 )
 
 type productCartHandler struct {
 	product product.Service
-	client  user.Service
 }
 
 func (h *productCartHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -47,7 +47,6 @@ func (h *productCartHandler) handleAddProductCart(w http.ResponseWriter, r *http
 
 	var (
 		err        error           // stores error in this handler
-		request    cartHTTP        // stores request
 		resBody    []byte          // stores response body to write
 		statusCode = http.StatusOK // stores response status code
 	)
@@ -65,7 +64,7 @@ func (h *productCartHandler) handleAddProductCart(w http.ResponseWriter, r *http
 	}()
 
 	// prepare channels for main go routine
-	resChan := make(chan struct{}, 1)
+	resChan := make(chan product.ProductCart, 1)
 	errChan := make(chan error, 1)
 
 	go func() {
@@ -78,6 +77,7 @@ func (h *productCartHandler) handleAddProductCart(w http.ResponseWriter, r *http
 		}
 
 		// unmarshall body
+		request := cartHTTP{}
 		err = json.Unmarshal(body, &request)
 		if err != nil {
 			statusCode = http.StatusBadRequest
@@ -93,6 +93,27 @@ func (h *productCartHandler) handleAddProductCart(w http.ResponseWriter, r *http
 			return
 		}
 
+		// check access of the authenticated user
+		tokenData, err := auth.Authorize(ctx, user.RequireRole(user.RoleCustomer, user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// the cart belongs to the caller unless stated otherwise
+		if reqProductCart.UserID == 0 {
+			reqProductCart.UserID = tokenData.UserID
+		}
+
+		// only the owner or admin can modify the cart
+		err = user.Authorize(tokenData, user.RequireOwnerOrAdmin(reqProductCart.UserID))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
 		err = h.product.AddProductCart(ctx, reqProductCart)
 		if err != nil {
 			// determine error and status code, by default its internal error
@@ -103,6 +124,11 @@ func (h *productCartHandler) handleAddProductCart(w http.ResponseWriter, r *http
 				statusCode = http.StatusBadRequest
 			}
 
+			// product is not available for the requested quantity
+			if err == product.ErrOutOfStock {
+				statusCode = http.StatusConflict
+			}
+
 			// log the actual error if its internal error
 			if statusCode == http.StatusInternalServerError {
 				log.Printf("[Product Cart HTTP][handleAddProductCart] Internal error from AddProductCart. Err: %s\n", err.Error())
@@ -112,7 +138,7 @@ func (h *productCartHandler) handleAddProductCart(w http.ResponseWriter, r *http
 			return
 		}
 
-		resChan <- struct{}{}
+		resChan <- reqProductCart
 	}()
 
 	// wait and handle main go routine
@@ -121,10 +147,10 @@ func (h *productCartHandler) handleAddProductCart(w http.ResponseWriter, r *http
 		statusCode = http.StatusGatewayTimeout
 		err = errRequestTimeout
 	case err = <-errChan:
-	case <-resChan:
+	case res := <-resChan:
 		resBody, err = json.Marshal(helper.ResponseEnvelope{
 			Data: map[string]interface{}{
-				"user_id":    request.UserID,
+				"user_id":    res.UserID,
 				"product_id": productID,
 			},
 		})
@@ -184,6 +210,27 @@ func (h *productCartHandler) handleDeleteProductCartByID(w http.ResponseWriter,
 			return
 		}
 
+		// check access of the authenticated user
+		tokenData, err := auth.Authorize(ctx, user.RequireRole(user.RoleCustomer, user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// the cart belongs to the caller unless stated otherwise
+		if reqProductCart.UserID == 0 {
+			reqProductCart.UserID = tokenData.UserID
+		}
+
+		// only the owner or admin can modify the cart
+		err = user.Authorize(tokenData, user.RequireOwnerOrAdmin(reqProductCart.UserID))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
 		err = h.product.DeleteProductCartByID(ctx, reqProductCart)
 		if err != nil {
 			// determine error and status code, by default its internal error
@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
@@ -10,19 +11,21 @@ import (
 	"time"
 
 	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
 	"github.com/synapsis-test/internal/product"
 	"github.com/synapsis-test/internal/user"
 )
 
 type productsHandler struct {
 	product product.Service
-	client  user.Service
 }
 
 func (h *productsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		h.handleGetProducts(w, r)
+	case http.MethodPost:
+		h.handleCreateProduct(w, r)
 	default:
 		helper.WriteErrorResponse(w, http.StatusMethodNotAllowed, []string{errMethodNotAllowed.Error()})
 	}
@@ -56,19 +59,11 @@ func (h *productsHandler) handleGetProducts(w http.ResponseWriter, r *http.Reque
 	errChan := make(chan error, 1)
 
 	go func() {
-		// get token from header
-		token, err := helper.GetBearerTokenFromHeader(r)
+		// check access of the authenticated user
+		_, err := auth.Authorize(ctx, user.RequireRole(user.RoleCustomer, user.RoleAdmin))
 		if err != nil {
-			statusCode = http.StatusBadRequest
-			errChan <- errInvalidToken
-			return
-		}
-
-		// check access token
-		err = checkAccessToken(ctx, h.client, token, "handleGetProducts")
-		if err != nil {
-			statusCode = http.StatusUnauthorized
-			errChan <- err
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
 			return
 		}
 
@@ -127,20 +122,127 @@ func (h *productsHandler) handleGetProducts(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+func (h *productsHandler) handleCreateProduct(w http.ResponseWriter, r *http.Request) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 3000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[Product HTTP][handleCreateProduct] Failed to create product. Err: %s\n", err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan int64, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// read body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		// unmarshall body
+		request := productHTTP{}
+		err = json.Unmarshal(body, &request)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		// check access of the authenticated user, only admin
+		// can change the catalogue
+		_, err = auth.Authorize(ctx, user.RequireRole(user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// format HTTP request into service object
+		reqProduct := parseProductFromRequest(request, product.Product{})
+
+		productID, err := h.product.CreateProduct(ctx, reqProduct)
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[Product HTTP][handleCreateProduct] Internal error from CreateProduct. Err: %s\n", err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- productID
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case productID := <-resChan:
+		resBody, err = json.Marshal(helper.ResponseEnvelope{
+			Status: "Success",
+			Data:   productID,
+		})
+	}
+}
+
 func parseGetProductsFilter(request url.Values) (product.GetProductsFilter, error) {
 	result := product.GetProductsFilter{}
 
-	var categoryID int64
 	if categoryIDStr := request.Get("category_id"); categoryIDStr != "" {
-		intCategoryID, err := strconv.ParseInt(categoryIDStr, 10, 64)
+		categoryID, err := strconv.ParseInt(categoryIDStr, 10, 64)
 		if err != nil {
 			return result, err
 		}
-		categoryID = intCategoryID
+		result.CategoryID = categoryID
+	}
+
+	result.Query = request.Get("q")
+
+	if limitStr := request.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return result, errInvalidLimit
+		}
+		result.Limit = limit
 	}
 
+	if offsetStr := request.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			return result, errInvalidOffset
+		}
+		result.Offset = offset
+	}
 
-	return product.GetProductsFilter{
-		CategoryID:  categoryID,
-	}, nil
+	return result, nil
 }
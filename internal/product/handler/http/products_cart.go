@@ -10,22 +10,29 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
 	"github.com/synapsis-test/internal/product"
 	"github.com/synapsis-test/internal/user"
 )
 
 type productsCartHandler struct {
 	product product.Service
-	client  user.Service
+	self    bool // serves the cart of the caller
 }
 
 func (h *productsCartHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	userID, err := strconv.ParseInt(vars["id"], 10, 64)
-	if err != nil {
-		log.Printf("[Product Cart HTTP][productsCartHandler] Failed to parse user ID. ID: %s. Err: %s\n", vars["id"], err.Error())
-		helper.WriteErrorResponse(w, http.StatusBadRequest, []string{errInvalidUserID.Error()})
-		return
+	// user ID of the caller is known after the token is
+	// checked, leave it empty for now
+	var userID int64
+	if !h.self {
+		vars := mux.Vars(r)
+		var err error
+		userID, err = strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			log.Printf("[Product Cart HTTP][productsCartHandler] Failed to parse user ID. ID: %s. Err: %s\n", vars["id"], err.Error())
+			helper.WriteErrorResponse(w, http.StatusBadRequest, []string{errInvalidUserID.Error()})
+			return
+		}
 	}
 
 	// handle based on HTTP request method
@@ -65,25 +72,29 @@ func (h *productsCartHandler) handleGetCartsByUserID(w http.ResponseWriter, r *h
 	errChan := make(chan error, 1)
 
 	go func() {
-		// get token from header
-		token, err := helper.GetBearerTokenFromHeader(r)
+		// check access of the authenticated user
+		tokenData, err := auth.Authorize(ctx, user.RequireRole(user.RoleCustomer, user.RoleAdmin))
 		if err != nil {
-			statusCode = http.StatusBadRequest
-			errChan <- errInvalidToken
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
 			return
 		}
 
-		// check access token
-		err = checkAccessToken(ctx, h.client, token, "handleGetCartsByUserID")
+		// resolve the cart owner
+		ownerID := userID
+		if h.self {
+			ownerID = tokenData.UserID
+		}
+
+		// only the owner or admin can see the cart
+		err = user.Authorize(tokenData, user.RequireOwnerOrAdmin(ownerID))
 		if err != nil {
-			statusCode = http.StatusUnauthorized
-			errChan <- err
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
 			return
 		}
 
-		// TODO: add authorization flow with roles
-
-		res, err := h.product.GetCartsByUserID(ctx, userID)
+		res, err := h.product.GetCartsByUserID(ctx, ownerID)
 		if err != nil {
 			// determine error and status code, by default its internal error
 			parsedErr := errInternalServer
@@ -95,7 +106,7 @@ func (h *productsCartHandler) handleGetCartsByUserID(w http.ResponseWriter, r *h
 
 			// log the actual error if its internal error
 			if statusCode == http.StatusInternalServerError {
-				log.Printf("[Product Cart HTTP][handleGetCartsByUserID] Internal error from GetCartsByUserID. userID: %d. Err: %s\n", userID, err.Error())
+				log.Printf("[Product Cart HTTP][handleGetCartsByUserID] Internal error from GetCartsByUserID. userID: %d. Err: %s\n", ownerID, err.Error())
 			}
 
 			errChan <- parsedErr
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
+	"github.com/synapsis-test/internal/product"
+	"github.com/synapsis-test/internal/user"
+)
+
+type productsSuggestHandler struct {
+	product product.Service
+}
+
+func (h *productsSuggestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// handle based on HTTP request method
+	switch r.Method {
+	case http.MethodGet:
+		h.handleSuggestProducts(w, r)
+	default:
+		helper.WriteErrorResponse(w, http.StatusMethodNotAllowed, []string{errMethodNotAllowed.Error()})
+	}
+}
+
+func (h *productsSuggestHandler) handleSuggestProducts(w http.ResponseWriter, r *http.Request) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 1000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[Product HTTP][handleSuggestProducts] Failed to suggest products. Err: %s\n", err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan []string, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// check access of the authenticated user
+		_, err := auth.Authorize(ctx, user.RequireRole(user.RoleCustomer, user.RoleAdmin))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// parse limit, zero uses the default one
+		var limit int
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			limit, err = strconv.Atoi(limitStr)
+			if err != nil {
+				statusCode = http.StatusBadRequest
+				errChan <- errInvalidLimit
+				return
+			}
+		}
+
+		res, err := h.product.SuggestProducts(ctx, r.URL.Query().Get("q"), limit)
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[Product HTTP][handleSuggestProducts] Internal error from SuggestProducts. Err: %s\n", err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- res
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case res := <-resChan:
+		resBody, err = json.Marshal(helper.ResponseEnvelope{
+			Status: "Success",
+			Data:   res,
+		})
+	}
+}
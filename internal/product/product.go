@@ -5,6 +5,12 @@ import (
 	"time"
 )
 
+// CacheVersionKey is the cache key of the version of
+// products, which is changed on every product change made
+// through Service, so the cached products are no longer
+// used.
+const CacheVersionKey = "product:version"
+
 type Service interface {
 	// GetProductByID returns a product with the given product ID.
 	GetProductByID(ctx context.Context, id int64) (Product, error)
@@ -13,6 +19,36 @@ type Service interface {
 	// filter.
 	GetProducts(ctx context.Context, filter GetProductsFilter) ([]Product, error)
 
+	// SuggestProducts returns at most the given limit of
+	// product names that match the given search query,
+	// including the names that only start with its words,
+	// best match first.
+	SuggestProducts(ctx context.Context, query string, limit int) ([]string, error)
+
+	// CreateProduct creates a new product as given, and
+	// returns the created product ID.
+	CreateProduct(ctx context.Context, product Product) (int64, error)
+
+	// UpdateProduct updates the product with the ID of the
+	// given product. It updates all the fields except ID,
+	// CreateTime and, unless updateStock is true, Stock, so
+	// make sure to use current values for the fields that
+	// should not be updated. The stock is left as is by
+	// default, as orders keep changing it.
+	UpdateProduct(ctx context.Context, product Product, updateStock bool) error
+
+	// DeleteProduct deletes the product with the given
+	// product ID. The product is kept in store, so the orders
+	// of the product are still intact.
+	DeleteProduct(ctx context.Context, id int64) error
+
+	// InvalidateCachedProducts removes the cached products
+	// with the given product IDs, after their stock is
+	// changed outside of the service, e.g. by orders. Cached
+	// lists of products are kept until they expire, so their
+	// stock may be behind meanwhile.
+	InvalidateCachedProducts(ctx context.Context, ids []int64) error
+
 	// AddProductCart add a prodcut to cart
 	AddProductCart(ctx context.Context, productCart ProductCart) error
 
@@ -28,6 +64,7 @@ type Product struct {
 	Name         string
 	Description  string
 	Price        int64
+	Stock        int64 // available quantity, excludes reserved ones
 	CategoryID   int64
 	CategoryName string //derived
 	CreateTime   time.Time
@@ -36,6 +73,15 @@ type Product struct {
 
 type GetProductsFilter struct {
 	CategoryID int64
+
+	// Query searches products by their name, description and
+	// category name, including the words that only start with
+	// the given ones. Products are sorted by best match first
+	// if it is given.
+	Query string
+
+	Limit  int
+	Offset int
 }
 
 type ProductCart struct {
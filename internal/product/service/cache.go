@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/synapsis-test/global/cache"
+	"github.com/synapsis-test/internal/category"
+	"github.com/synapsis-test/internal/product"
+)
+
+// productCacheTTL is how long products are cached.
+const productCacheTTL = 5 * time.Minute
+
+// productCachePrefix is the prefix of the cache keys of
+// products.
+const productCachePrefix = "product:data:"
+
+// productsCacheFilter denotes the normalised products filter
+// used to derive the cache key, so the same filter always
+// results in the same key.
+type productsCacheFilter struct {
+	CategoryID int64  `json:"category_id"`
+	Query      string `json:"query"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+}
+
+// getThroughCache reads the cached value of the given name
+// into the given destination, otherwise it loads the value
+// using the given load function and caches it.
+func (s *service) getThroughCache(ctx context.Context, name string, dst interface{}, load func() (interface{}, error)) error {
+	return s.readThrough.Get(ctx, func(ctx context.Context) (string, error) {
+		return s.formatCacheKey(ctx, name)
+	}, dst, load)
+}
+
+// invalidateProductsCache changes the version of products,
+// so the next reads get the changes from store. The products
+// cached in the previous versions are left to expire. The
+// change is already stored, so the failure is only logged.
+func (s *service) invalidateProductsCache(ctx context.Context) {
+	err := cache.BumpVersion(ctx, s.cache, product.CacheVersionKey, s.timeNow())
+	if err != nil {
+		log.Printf("[Product Service][invalidateProductsCache] Failed to invalidate products cache. Err: %s\n", err.Error())
+	}
+}
+
+// InvalidateCachedProducts removes the cached products with
+// the given product IDs in the current versions, so the next
+// reads of them get the changes from store.
+func (s *service) InvalidateCachedProducts(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, formatProductCacheName(id))
+	}
+
+	keys, err := s.formatCacheKeys(ctx, names...)
+	if err != nil {
+		return err
+	}
+
+	return s.cache.Delete(ctx, keys...)
+}
+
+// formatCacheKey returns the cache key of the given name. The
+// key contains the current versions of products and
+// categories, as the products also carry their category name.
+func (s *service) formatCacheKey(ctx context.Context, name string) (string, error) {
+	keys, err := s.formatCacheKeys(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return keys[0], nil
+}
+
+// formatCacheKeys returns the cache keys of the given names,
+// reading the current versions only once.
+func (s *service) formatCacheKeys(ctx context.Context, names ...string) ([]string, error) {
+	productVersion, err := cache.GetVersion(ctx, s.cache, product.CacheVersionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryVersion, err := cache.GetVersion(ctx, s.cache, category.CacheVersionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, fmt.Sprintf("%sv%s.%s:%s", productCachePrefix, productVersion, categoryVersion, name))
+	}
+	return keys, nil
+}
+
+// formatProductCacheName returns the cache name of the
+// product with the given product ID.
+func formatProductCacheName(id int64) string {
+	return fmt.Sprintf("item:%d", id)
+}
+
+// formatProductsCacheName returns the cache name of the list
+// of products of the given filter, which is the hash of the
+// filter after it is normalised. The query should already be
+// normalised.
+func formatProductsCacheName(filter product.GetProductsFilter) (string, error) {
+	normalised := productsCacheFilter{
+		CategoryID: filter.CategoryID,
+		Query:      filter.Query,
+		Limit:      filter.Limit,
+		Offset:     filter.Offset,
+	}
+
+	// non-positive category ID is not filtered
+	if normalised.CategoryID < 0 {
+		normalised.CategoryID = 0
+	}
+
+	filterJSON, err := json.Marshal(normalised)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(filterJSON)
+	return "list:" + hex.EncodeToString(sum[:]), nil
+}
+
+// formatSuggestionsCacheName returns the cache name of the
+// product names suggested for the given normalised query and
+// limit.
+func formatSuggestionsCacheName(query string, limit int) string {
+	sum := sha256.Sum256([]byte(query))
+	return fmt.Sprintf("suggest:%d:%s", limit, hex.EncodeToString(sum[:]))
+}
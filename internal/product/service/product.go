@@ -2,10 +2,11 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"time"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
-	"github.com/go-redis/redis/v8"
+	"github.com/synapsis-test/internal/category"
 	"github.com/synapsis-test/internal/product"
 )
 
@@ -16,14 +17,17 @@ func (s *service) GetProductByID(ctx context.Context, id int64) (product.Product
 		return product.Product{}, product.ErrInvalidProductID
 	}
 
-	// get pg store client
-	pgStoreClient, err := s.pgStore.NewClient(false)
-	if err != nil {
-		return product.Product{}, err
-	}
+	var result product.Product
+	err := s.getThroughCache(ctx, formatProductCacheName(id), &result, func() (interface{}, error) {
+		// get pg store client
+		pgStoreClient, err := s.pgStore.NewClient(false)
+		if err != nil {
+			return nil, err
+		}
 
-	// get a product from postgre
-	result, err := pgStoreClient.GetProductByID(ctx, id)
+		// get a product from postgre
+		return pgStoreClient.GetProductByID(ctx, id)
+	})
 	if err != nil {
 		return product.Product{}, err
 	}
@@ -34,38 +38,233 @@ func (s *service) GetProductByID(ctx context.Context, id int64) (product.Product
 // GetProducts returns list of products that satisfy the given
 // filter.
 func (s *service) GetProducts(ctx context.Context, filter product.GetProductsFilter) ([]product.Product, error) {
-	// get from redis
-	resultCache, err := s.redisClient.Get(ctx, "allProducts").Result()
-	if err == nil {
-		var products []product.Product
-		if err := json.Unmarshal([]byte(resultCache), &products); err != nil {
-			return nil, err
-		}
-		return products, nil
-	} else if err != redis.Nil {
+	// validate filter
+	err := validateGetProductsFilter(filter)
+	if err != nil {
 		return nil, err
 	}
 
-	// get pg store client
-	pgStoreClient, err := s.pgStore.NewClient(false)
-	if err != nil {
-		return nil, err
+	// search by the words of the query only
+	if filter.Query != "" {
+		filter.Query = normalizeSearchQuery(filter.Query)
+		if filter.Query == "" {
+			return nil, product.ErrInvalidQuery
+		}
 	}
 
-	// get all products from postgre
-	result, err := pgStoreClient.GetProducts(ctx, filter)
+	// use default limit if not given
+	if filter.Limit == 0 {
+		filter.Limit = defaultGetProductsLimit
+	}
+
+	name, err := formatProductsCacheName(filter)
 	if err != nil {
 		return nil, err
 	}
 
-	// Cache the data for requests
-	resultsJSON, err := json.Marshal(result)
+	var result []product.Product
+	err = s.getThroughCache(ctx, name, &result, func() (interface{}, error) {
+		// get pg store client
+		pgStoreClient, err := s.pgStore.NewClient(false)
+		if err != nil {
+			return nil, err
+		}
+
+		// get products from postgre
+		return pgStoreClient.GetProducts(ctx, filter)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if err := s.redisClient.Set(ctx, "allProducts", resultsJSON, 12*time.Hour).Err(); err != nil {
+
+	return result, nil
+}
+
+// SuggestProducts returns at most the given limit of product
+// names that match the given search query, including the
+// names that only start with its words, best match first.
+func (s *service) SuggestProducts(ctx context.Context, query string, limit int) ([]string, error) {
+	// validate the given values
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, product.ErrInvalidQuery
+	}
+	query = normalizeSearchQuery(query)
+	if query == "" {
+		return nil, product.ErrInvalidQuery
+	}
+
+	if limit < 0 || limit > maxSuggestProductsLimit {
+		return nil, product.ErrInvalidLimit
+	}
+
+	// use default limit if not given
+	if limit == 0 {
+		limit = defaultSuggestProductsLimit
+	}
+
+	var result []string
+	err := s.getThroughCache(ctx, formatSuggestionsCacheName(query, limit), &result, func() (interface{}, error) {
+		// get pg store client
+		pgStoreClient, err := s.pgStore.NewClient(false)
+		if err != nil {
+			return nil, err
+		}
+
+		// get product names from postgre
+		return pgStoreClient.SuggestProductNames(ctx, query, limit)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
+
+// CreateProduct creates a new product as given, and returns
+// the created product ID.
+func (s *service) CreateProduct(ctx context.Context, reqProduct product.Product) (int64, error) {
+	// validate fields
+	err := s.validateProduct(ctx, reqProduct)
+	if err != nil {
+		return 0, err
+	}
+
+	// update fields
+	reqProduct.CreateTime = s.timeNow()
+
+	// get pg store client without transaction
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return 0, err
+	}
+
+	// insert product in pgstore
+	productID, err := pgStoreClient.CreateProduct(ctx, reqProduct)
+	if err != nil {
+		return 0, err
+	}
+
+	s.invalidateProductsCache(ctx)
+
+	return productID, nil
+}
+
+// UpdateProduct updates the product with the ID of the given
+// product. It updates all the fields except ID and
+// CreateTime, so make sure to use current values for the
+// fields that should not be updated.
+func (s *service) UpdateProduct(ctx context.Context, reqProduct product.Product, updateStock bool) error {
+	// validate fields
+	if reqProduct.ID <= 0 {
+		return product.ErrInvalidProductID
+	}
+	err := s.validateProduct(ctx, reqProduct)
+	if err != nil {
+		return err
+	}
+
+	// update fields
+	reqProduct.UpdateTime = s.timeNow()
+
+	// get pg store client without transaction
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return err
+	}
+
+	// update product in pgstore
+	err = pgStoreClient.UpdateProduct(ctx, reqProduct, updateStock)
+	if err != nil {
+		return err
+	}
+
+	s.invalidateProductsCache(ctx)
+
+	return nil
+}
+
+// DeleteProduct deletes the product with the given product
+// ID. The product is kept in store, so the orders of the
+// product are still intact.
+func (s *service) DeleteProduct(ctx context.Context, id int64) error {
+	// validate id
+	if id <= 0 {
+		return product.ErrInvalidProductID
+	}
+
+	// get pg store client without transaction
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return err
+	}
+
+	// mark product as deleted in pgstore
+	err = pgStoreClient.DeleteProduct(ctx, id, s.timeNow())
+	if err != nil {
+		return err
+	}
+
+	s.invalidateProductsCache(ctx)
+
+	return nil
+}
+
+// validateGetProductsFilter validates fields of the given
+// filter.
+func validateGetProductsFilter(filter product.GetProductsFilter) error {
+	if utf8.RuneCountInString(filter.Query) > maxSearchQueryLength {
+		return product.ErrInvalidQuery
+	}
+
+	if filter.Limit < 0 || filter.Limit > maxGetProductsLimit {
+		return product.ErrInvalidLimit
+	}
+
+	if filter.Offset < 0 {
+		return product.ErrInvalidOffset
+	}
+
+	return nil
+}
+
+// normalizeSearchQuery returns the lowercase words of the
+// given search query separated by a space. Other characters
+// than letters and digits separate words, so they can not
+// change the meaning of the text search query.
+func normalizeSearchQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, " ")
+}
+
+// validateProduct validates fields of the given product, and
+// whether its category exists.
+func (s *service) validateProduct(ctx context.Context, reqProduct product.Product) error {
+	if strings.TrimSpace(reqProduct.Name) == "" {
+		return product.ErrInvalidName
+	}
+
+	if reqProduct.Price <= 0 {
+		return product.ErrInvalidPrice
+	}
+
+	if reqProduct.Stock < 0 {
+		return product.ErrInvalidStock
+	}
+
+	if reqProduct.CategoryID <= 0 {
+		return product.ErrInvalidCategoryID
+	}
+
+	_, err := s.category.GetCategoryByID(ctx, reqProduct.CategoryID)
+	if err == category.ErrDataNotFound {
+		return product.ErrInvalidCategoryID
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
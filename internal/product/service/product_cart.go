@@ -22,6 +22,17 @@ func (s *service) AddProductCart(ctx context.Context, reqProductCart product.Pro
 		return err
 	}
 
+	// make sure the product is still available, the stock is
+	// only reserved when the order is created
+	p, err := pgStoreClient.GetProductByID(ctx, reqProductCart.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if p.Stock < reqProductCart.Quantity {
+		return product.ErrOutOfStock
+	}
+
 	// add product cart in pgstore
 	err = pgStoreClient.AddProductCart(ctx, reqProductCart)
 	if err != nil {
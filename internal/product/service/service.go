@@ -3,22 +3,45 @@ package service
 import (
 	"time"
 
-	"github.com/go-redis/redis/v8"
+	"github.com/synapsis-test/global/cache"
+	"github.com/synapsis-test/internal/category"
 	"github.com/synapsis-test/internal/product/store/postgresql"
 )
 
+// Followings are the limit of products returned in
+// GetProducts.
+const (
+	defaultGetProductsLimit = 20
+	maxGetProductsLimit     = 100
+)
+
+// Followings are the limit of product names returned in
+// SuggestProducts.
+const (
+	defaultSuggestProductsLimit = 5
+	maxSuggestProductsLimit     = 20
+)
+
+// maxSearchQueryLength is the maximum number of characters of
+// a search query.
+const maxSearchQueryLength = 100
+
 // service implements user.Service.
 type service struct {
 	pgStore     postgresql.PGStore
-	redisClient *redis.Client
+	category    category.Service
+	cache       cache.Cache
+	readThrough *cache.ReadThrough
 	timeNow     func() time.Time
 }
 
 // New creates a new service.
-func New(pgStore postgresql.PGStore, redisClient *redis.Client) (*service, error) {
+func New(pgStore postgresql.PGStore, category category.Service, cacheClient cache.Cache) (*service, error) {
 	s := &service{
 		pgStore:     pgStore,
-		redisClient: redisClient,
+		category:    category,
+		cache:       cacheClient,
+		readThrough: cache.NewReadThrough(cacheClient, productCacheTTL),
 		timeNow:     time.Now,
 	}
 
@@ -69,6 +69,7 @@ type productDB struct {
 	ID           int64      `db:"id"`
 	Name         string     `db:"name"`
 	Price        int64      `db:"price"`
+	Stock        int64      `db:"stock"`
 	Description  string     `db:"description"`
 	CategoryID   int64      `db:"category_id"`
 	CategoryName string     `db:"category_name"`
@@ -82,6 +83,7 @@ func (pdb *productDB) format() product.Product {
 		ID:           pdb.ID,
 		Name:         pdb.Name,
 		Price:        pdb.Price,
+		Stock:        pdb.Stock,
 		Description:  pdb.Description,
 		CategoryID:   pdb.CategoryID,
 		CategoryName: pdb.CategoryName,
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/synapsis-test/internal/product"
@@ -12,7 +13,7 @@ import (
 
 // GetProductByID returns a product with the given product ID.
 func (sc *storeClient) GetProductByID(ctx context.Context, id int64) (product.Product, error) {
-	query := fmt.Sprintf(queryGetProduct, "WHERE p.id = $1")
+	query := fmt.Sprintf(queryGetProduct, "WHERE p.id = $1 AND p.deleted_at IS NULL")
 	// query single row
 	var pdb productDB
 	err := sc.q.QueryRowx(query, id).StructScan(&pdb)
@@ -31,13 +32,18 @@ func (sc *storeClient) GetProductByID(ctx context.Context, id int64) (product.Pr
 func (sc *storeClient) GetProducts(ctx context.Context, filter product.GetProductsFilter) ([]product.Product, error) {
 	// define variables to custom query
 	argsKV := make(map[string]interface{})
-	addConditions := make([]string, 0)
+	addConditions := []string{"p.deleted_at IS NULL"}
 
 	if filter.CategoryID > 0 {
 		addConditions = append(addConditions, "p.category_id = :category_id")
 		argsKV["category_id"] = filter.CategoryID
 	}
 
+	if filter.Query != "" {
+		addConditions = append(addConditions, productSearchCondition)
+		argsKV["query"] = formatPrefixSearchQuery(filter.Query, "")
+	}
+
 	// construct strings to custom query
 	addCondition := strings.Join(addConditions, " AND ")
 
@@ -47,6 +53,25 @@ func (sc *storeClient) GetProducts(ctx context.Context, filter product.GetProduc
 		addCondition = fmt.Sprintf("WHERE %s", addCondition)
 	}
 
+	// sort by best match first if searching, product ID
+	// breaks the tie so pagination is stable
+	if filter.Query != "" {
+		addCondition = fmt.Sprintf("%s ORDER BY ts_rank(%s, %s) DESC, p.id ASC", addCondition, productSearchDocument, productSearchQuery)
+	} else {
+		addCondition = fmt.Sprintf("%s ORDER BY p.id ASC", addCondition)
+	}
+
+	// paginate
+	if filter.Limit > 0 {
+		addCondition = fmt.Sprintf("%s LIMIT :limit", addCondition)
+		argsKV["limit"] = filter.Limit
+	}
+
+	if filter.Offset > 0 {
+		addCondition = fmt.Sprintf("%s OFFSET :offset", addCondition)
+		argsKV["offset"] = filter.Offset
+	}
+
 	// construct query
 	query := fmt.Sprintf(queryGetProduct, addCondition)
 
@@ -86,3 +111,181 @@ func (sc *storeClient) GetProducts(ctx context.Context, filter product.GetProduc
 
 	return products, nil
 }
+
+// SuggestProductNames returns at most the given limit of
+// distinct product names that match the given search query
+// by their prefix, best match first.
+func (sc *storeClient) SuggestProductNames(ctx context.Context, query string, limit int) ([]string, error) {
+	argsKV := map[string]interface{}{
+		"query": formatPrefixSearchQuery(query, productNameSearchWeight),
+		"limit": limit,
+	}
+
+	// prepare query
+	stmt, args, err := sqlx.Named(querySuggestProductNames, argsKV)
+	if err != nil {
+		return nil, err
+	}
+	stmt = sc.q.Rebind(stmt)
+
+	// query to database
+	rows, err := sc.q.Queryx(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// read names
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// CreateProduct inserts the given product.
+//
+// CreateProduct returns created product ID.
+func (sc *storeClient) CreateProduct(ctx context.Context, reqProduct product.Product) (int64, error) {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"name":        reqProduct.Name,
+		"description": reqProduct.Description,
+		"price":       reqProduct.Price,
+		"stock":       reqProduct.Stock,
+		"category_id": reqProduct.CategoryID,
+		"create_time": reqProduct.CreateTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryCreateProduct, argsKV)
+	if err != nil {
+		return 0, err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	var productID int64
+	err = sc.q.QueryRowx(query, args...).Scan(&productID)
+	if err != nil {
+		return 0, err
+	}
+
+	return productID, nil
+}
+
+// UpdateProduct updates all the fields except ID and
+// CreateTime of the product with the ID of the given product.
+// The given stock is the available stock.
+//
+// It returns product.ErrDataNotFound if there is no such
+// product or it is deleted.
+func (sc *storeClient) UpdateProduct(ctx context.Context, reqProduct product.Product, updateStock bool) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"id":           reqProduct.ID,
+		"name":         reqProduct.Name,
+		"description":  reqProduct.Description,
+		"price":        reqProduct.Price,
+		"stock":        reqProduct.Stock,
+		"update_stock": updateStock,
+		"category_id":  reqProduct.CategoryID,
+		"update_time":  reqProduct.UpdateTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryUpdateProduct, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	result, err := sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return product.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// DeleteProduct marks the product with the given product ID
+// as deleted at the given delete time.
+//
+// It returns product.ErrDataNotFound if there is no such
+// product or it is already deleted.
+func (sc *storeClient) DeleteProduct(ctx context.Context, id int64, deleteTime time.Time) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"id":         id,
+		"deleted_at": deleteTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryDeleteProduct, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	result, err := sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return product.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// formatPrefixSearchQuery returns the text search query of
+// the given search query, which words are separated by space
+// and only contain letters and digits. Every word must match
+// by its prefix, so it works while the query is being typed,
+// and only the given weights of the document if any.
+func formatPrefixSearchQuery(query string, weights string) string {
+	words := strings.Fields(query)
+	for i := range words {
+		words[i] += ":*" + weights
+	}
+
+	return strings.Join(words, " & ")
+}
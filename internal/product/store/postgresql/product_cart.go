@@ -41,7 +41,7 @@ func (sc *storeClient) AddProductCart(ctx context.Context, reqProductCart produc
 func (sc *storeClient) GetCartsByUserID(ctx context.Context, userID int64) ([]product.ProductCart, error) {
 	// define variables to custom query
 	argsKV := make(map[string]interface{})
-	addConditions := make([]string, 0)
+	addConditions := []string{"p.deleted_at IS NULL"} // deleted products can not be ordered
 
 	if userID > 0 {
 		addConditions = append(addConditions, "pc.user_id = :user_id")
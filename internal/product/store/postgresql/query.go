@@ -5,6 +5,7 @@ const queryGetProduct = `
 		p.id,
 		p.name,
 		p.price,
+		p.stock - p.reserved_stock AS stock,
 		p.description,
 		p.category_id,
 		c.name AS category_name,
@@ -19,6 +20,99 @@ const queryGetProduct = `
 	%s
 `
 
+// productSearchDocument is the text search document of a
+// product, which weights its name over its description and
+// its category name. The search vectors are generated columns
+// using the "simple" configuration, so words are not stemmed
+// and can be matched by their prefix.
+const productSearchDocument = `(p.search_vector || COALESCE(c.search_vector, ''::tsvector))`
+
+// productNameSearchWeight is the weight of product name in
+// the product search vector.
+const productNameSearchWeight = "A"
+
+// productSearchQuery is the text search query of the given
+// prefix search query.
+const productSearchQuery = `to_tsquery('simple', :query)`
+
+// productSearchCondition matches the products whose search
+// document matches the search query, so the words of a query
+// can match either the product or its category, the same way
+// the products are ranked.
+const productSearchCondition = productSearchDocument + ` @@ ` + productSearchQuery
+
+// querySuggestProductNames returns the distinct names of the
+// products that match the given prefix search query, best
+// match first. The query should only match the name weight
+// of the search vector.
+const querySuggestProductNames = `
+	SELECT
+		p.name
+	FROM
+		product p
+	WHERE
+		p.deleted_at IS NULL
+	AND
+		p.search_vector @@ to_tsquery('simple', :query)
+	GROUP BY
+		p.name
+	ORDER BY
+		MAX(ts_rank(p.search_vector, to_tsquery('simple', :query))) DESC,
+		p.name ASC
+	LIMIT
+		:limit
+`
+
+const queryCreateProduct = `
+	INSERT INTO
+		product
+	(
+		name,
+		description,
+		price,
+		stock,
+		category_id,
+		create_time
+	) VALUES (
+		:name,
+		:description,
+		:price,
+		:stock,
+		:category_id,
+		:create_time
+	) RETURNING
+		id
+`
+
+// queryUpdateProduct sets the available stock, so the
+// reserved stock is added to it.
+const queryUpdateProduct = `
+	UPDATE
+		product
+	SET
+		name = :name,
+		description = :description,
+		price = :price,
+		stock = CASE WHEN :update_stock THEN :stock + reserved_stock ELSE stock END,
+		category_id = :category_id,
+		update_time = :update_time
+	WHERE
+		id = :id
+	AND
+		deleted_at IS NULL
+`
+
+const queryDeleteProduct = `
+	UPDATE
+		product
+	SET
+		deleted_at = :deleted_at
+	WHERE
+		id = :id
+	AND
+		deleted_at IS NULL
+`
+
 const queryAddProductCart = `
 	INSERT INTO
 		product_cart
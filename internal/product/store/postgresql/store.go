@@ -2,6 +2,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/synapsis-test/internal/product"
 )
@@ -23,6 +24,33 @@ type PGStoreClient interface {
 	// filter.
 	GetProducts(ctx context.Context, filter product.GetProductsFilter) ([]product.Product, error)
 
+	// SuggestProductNames returns at most the given limit of
+	// distinct product names that match the given search
+	// query by their prefix, best match first.
+	SuggestProductNames(ctx context.Context, query string, limit int) ([]string, error)
+
+	// CreateProduct inserts the given product.
+	//
+	// CreateProduct returns created product ID.
+	CreateProduct(ctx context.Context, product product.Product) (int64, error)
+
+	// UpdateProduct updates all the fields except ID and
+	// CreateTime of the product with the ID of the given
+	// product. The stock is only updated if stated, as the
+	// given stock is the available stock and the reserved
+	// stock is added to it in the same statement.
+	//
+	// It returns product.ErrDataNotFound if there is no such
+	// product or it is deleted.
+	UpdateProduct(ctx context.Context, product product.Product, updateStock bool) error
+
+	// DeleteProduct marks the product with the given product
+	// ID as deleted at the given delete time.
+	//
+	// It returns product.ErrDataNotFound if there is no such
+	// product or it is already deleted.
+	DeleteProduct(ctx context.Context, id int64, deleteTime time.Time) error
+
 	// AddProductCart add a prodcut to cart
 	AddProductCart(ctx context.Context, productCart product.ProductCart) error
 
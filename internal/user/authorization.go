@@ -0,0 +1,47 @@
+package user
+
+// Requirement denotes an authorization requirement that the
+// data encapsulated in a token should satisfy.
+type Requirement func(data TokenData) bool
+
+// Authorize checks the given token data against all the
+// given requirements. It returns ErrForbidden if any of the
+// requirements is not satisfied.
+func Authorize(data TokenData, requirements ...Requirement) error {
+	for _, requirement := range requirements {
+		if !requirement(data) {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
+
+// RequireRole returns Requirement that is satisfied when
+// the token belongs to a user with any of the given roles.
+func RequireRole(roles ...Role) Requirement {
+	return func(data TokenData) bool {
+		for _, role := range roles {
+			if data.Role == role {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RequireOwner returns Requirement that is satisfied when
+// the token belongs to the user with the given user ID.
+func RequireOwner(ownerID int64) Requirement {
+	return func(data TokenData) bool {
+		return data.UserID == ownerID
+	}
+}
+
+// RequireOwnerOrAdmin returns Requirement that is satisfied
+// when the token belongs to the user with the given user ID
+// or to an admin.
+func RequireOwnerOrAdmin(ownerID int64) Requirement {
+	return func(data TokenData) bool {
+		return data.UserID == ownerID || data.Role == RoleAdmin
+	}
+}
@@ -0,0 +1,23 @@
+package user
+
+import "context"
+
+// contextKey is the type of keys of values stored in
+// context by user, so it never collides with others.
+type contextKey int
+
+// tokenDataContextKey is the context key of TokenData.
+const tokenDataContextKey contextKey = 0
+
+// NewContext returns a new context that carries the given
+// token data.
+func NewContext(ctx context.Context, data TokenData) context.Context {
+	return context.WithValue(ctx, tokenDataContextKey, data)
+}
+
+// TokenDataFromContext returns the token data carried in
+// the given context, if any.
+func TokenDataFromContext(ctx context.Context) (TokenData, bool) {
+	data, ok := ctx.Value(tokenDataContextKey).(TokenData)
+	return data, ok
+}
@@ -9,6 +9,11 @@ var (
 	// unique constraints.
 	ErrUserAlreadyExist = errors.New("user already exist")
 
+	// ErrAdminEmailTaken is returned when the email of the
+	// admin to bootstrap is already used by a user who is not
+	// an admin.
+	ErrAdminEmailTaken = errors.New("admin email is used by a non-admin user")
+
 	// ErrDataNotFound is returned when the desired data is
 	// not found.
 	ErrDataNotFound = errors.New("data not found")
@@ -21,10 +26,18 @@ var (
 	// is invalid.
 	ErrInvalidPassword = errors.New("invalid password")
 
+	// ErrWeakPassword is returned when the given password
+	// does not comply the password strength policy.
+	ErrWeakPassword = errors.New("weak password")
+
 	// ErrInvalidToken is returned when the given token is
 	// invalid.
 	ErrInvalidToken = errors.New("invalid token")
 
+	// ErrRevokedToken is returned when the given token is
+	// revoked.
+	ErrRevokedToken = errors.New("revoked token")
+
 	// ErrInvalidEmail is returned when the given email is
 	// invalid.
 	ErrInvalidEmail = errors.New("invalid email")
@@ -40,4 +53,20 @@ var (
 	// ErrInvalidPhoneNumber is returned when the given phone number is
 	// invalid.
 	ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+	// ErrEmailNotVerified is returned when the email of the
+	// user is required to be verified but it is not.
+	ErrEmailNotVerified = errors.New("email not verified")
+
+	// ErrTooManyRequests is returned when the same request is
+	// made again too soon.
+	ErrTooManyRequests = errors.New("too many requests")
+
+	// ErrAccountLocked is returned when the account is
+	// temporarily locked after too many failed logins.
+	ErrAccountLocked = errors.New("account locked")
+
+	// ErrForbidden is returned when the token data does not
+	// satisfy the authorization requirements.
+	ErrForbidden = errors.New("forbidden")
 )
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/user"
+)
+
+type emailVerificationHandler struct {
+	user user.Service
+}
+
+func (h *emailVerificationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// handle based on HTTP request method
+	switch r.Method {
+	case http.MethodGet:
+		h.handleVerifyEmail(w, r)
+	default:
+		helper.WriteErrorResponse(w, http.StatusMethodNotAllowed, []string{errMethodNotAllowed.Error()})
+	}
+}
+
+func (h *emailVerificationHandler) handleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 3000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[User HTTP][handleVerifyEmail] Failed to verify email. Err: %s\n", err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan struct{}, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// verify email
+		err := h.user.VerifyEmail(ctx, r.URL.Query().Get("token"))
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[User HTTP][handleVerifyEmail] Internal error from VerifyEmail. Err: %s\n", err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- struct{}{}
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case <-resChan:
+		res := helper.ResponseEnvelope{
+			Status: "Success",
+		}
+		resBody, err = json.Marshal(res)
+	}
+}
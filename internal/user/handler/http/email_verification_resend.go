@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/user"
+)
+
+type emailVerificationResendHandler struct {
+	user user.Service
+}
+
+// emailVerificationResendRequestData is the data from user
+// to resend email verification.
+type emailVerificationResendRequestData struct {
+	Email string `json:"email"`
+}
+
+func (h *emailVerificationResendHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// handle based on HTTP request method
+	switch r.Method {
+	case http.MethodPost:
+		h.handleResendEmailVerification(w, r)
+	default:
+		helper.WriteErrorResponse(w, http.StatusMethodNotAllowed, []string{errMethodNotAllowed.Error()})
+	}
+}
+
+func (h *emailVerificationResendHandler) handleResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 3000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[User HTTP][handleResendEmailVerification] Failed to resend email verification. Err: %s\n", err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan struct{}, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		// unmarshall body
+		var data emailVerificationResendRequestData
+		err = json.Unmarshal(body, &data)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		// resend email verification, the result is the same
+		// whether the email exists or not
+		err = h.user.ResendEmailVerification(ctx, data.Email)
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// resent too soon
+			if err == user.ErrTooManyRequests {
+				statusCode = http.StatusTooManyRequests
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[User HTTP][handleResendEmailVerification] Internal error from ResendEmailVerification. Err: %s\n", err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- struct{}{}
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case <-resChan:
+		res := helper.ResponseEnvelope{
+			Status: "Success",
+		}
+		resBody, err = json.Marshal(res)
+	}
+}
@@ -33,10 +33,18 @@ var (
 	// is invalid.
 	errInvalidPassword = errors.New("INVALID_PASSWORD")
 
+	// errWeakPassword is returned when the given password
+	// does not comply the password strength policy.
+	errWeakPassword = errors.New("WEAK_PASSWORD")
+
 	// errInvalidToken is returned when the given token is
 	// invalid.
 	errInvalidToken = errors.New("INVALID_TOKEN")
 
+	// errRevokedToken is returned when the given token is
+	// revoked.
+	errRevokedToken = errors.New("REVOKED_TOKEN")
+
 	// errInvalidEmail is returned when the given email
 	// is invalid.
 	errInvalidEmail = errors.New("INVALID_EMAIL")
@@ -53,9 +61,21 @@ var (
 	// invalid.
 	errInvalidUserID = errors.New("INVALID_USER_ID")
 
-	// errUnauthorizedAccess is returned when the request
-	// is unaothorized.
-	errUnauthorizedAccess = errors.New("UNAUTHORIZED_ACCESS")
+	// errEmailNotVerified is returned when the email of the
+	// user is required to be verified but it is not.
+	errEmailNotVerified = errors.New("EMAIL_NOT_VERIFIED")
+
+	// errTooManyRequests is returned when the same request is
+	// made again too soon.
+	errTooManyRequests = errors.New("TOO_MANY_REQUESTS")
+
+	// errAccountLocked is returned when the account is
+	// temporarily locked after too many failed logins.
+	errAccountLocked = errors.New("ACCOUNT_LOCKED")
+
+	// errForbiddenAccess is returned when the request is
+	// authenticated but not allowed.
+	errForbiddenAccess = errors.New("FORBIDDEN_ACCESS")
 )
 
 var (
@@ -71,6 +91,11 @@ var (
 		user.ErrInvalidEmail:     errInvalidEmail,
 		user.ErrExpiredToken:     errExpiredToken,
 		user.ErrInvalidPassword:  errInvalidPassword,
+		user.ErrWeakPassword:     errWeakPassword,
 		user.ErrInvalidToken:     errInvalidToken,
+		user.ErrRevokedToken:     errRevokedToken,
+		user.ErrEmailNotVerified: errEmailNotVerified,
+		user.ErrTooManyRequests:  errTooManyRequests,
+		user.ErrAccountLocked:    errAccountLocked,
 	}
 )
@@ -3,6 +3,7 @@ package http
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/synapsis-test/internal/user"
@@ -28,6 +29,10 @@ type handler struct {
 type HandlerIdentity struct {
 	Name string
 	URL  string
+
+	// Public serves the handler without authentication,
+	// otherwise only authenticated requests are served.
+	Public bool
 }
 
 // Followings are the known HTTP handler identities
@@ -35,14 +40,16 @@ var (
 	// HandlerUsers denotes HTTP handler to interact
 	// with users
 	HandlerUsers = HandlerIdentity{
-		Name: "users",
-		URL:  "/v1/user",
+		Name:   "users",
+		URL:    "/v1/user",
+		Public: true,
 	}
 
 	// HandlerLogin denotes HTTP handler for user to login.
 	HandlerLogin = HandlerIdentity{
-		Name: "login",
-		URL:  "/v1/user/login",
+		Name:   "login",
+		URL:    "/v1/user/login",
+		Public: true,
 	}
 
 	// HandlerPassword denotes HTTP handler for user to
@@ -52,11 +59,72 @@ var (
 		URL:  "/v1/user/password/{id}",
 	}
 
-	// HandlerToken denotes HTTP handler for user to interact
-	// with access token.
+	// HandlerToken denotes HTTP handler for user to exchange
+	// refresh token with a new pair of token.
 	HandlerToken = HandlerIdentity{
-		Name: "token",
-		URL:  "/v1/user/token/{id}",
+		Name:   "token",
+		URL:    "/v1/user/token",
+		Public: true,
+	}
+
+	// HandlerPasswordForgot denotes HTTP handler for user who
+	// forgot the password to request a password reset token.
+	HandlerPasswordForgot = HandlerIdentity{
+		Name:   "password-forgot",
+		URL:    "/v1/password/forgot",
+		Public: true,
+	}
+
+	// HandlerPasswordReset denotes HTTP handler for user to
+	// reset password using a password reset token.
+	HandlerPasswordReset = HandlerIdentity{
+		Name:   "password-reset",
+		URL:    "/v1/password/reset",
+		Public: true,
+	}
+
+	// HandlerEmailVerification denotes HTTP handler for user
+	// to verify email using an email verification token.
+	HandlerEmailVerification = HandlerIdentity{
+		Name:   "email-verification",
+		URL:    "/v1/users/verify",
+		Public: true,
+	}
+
+	// HandlerEmailVerificationResend denotes HTTP handler for
+	// user to request a new email verification token.
+	HandlerEmailVerificationResend = HandlerIdentity{
+		Name:   "email-verification-resend",
+		URL:    "/v1/users/verify/resend",
+		Public: true,
+	}
+
+	// HandlerLogout denotes HTTP handler for user to logout.
+	HandlerLogout = HandlerIdentity{
+		Name: "logout",
+		URL:  "/v1/logout",
+	}
+
+	// HandlerProfile denotes HTTP handler to interact with
+	// user profile data. The ID is numeric so it does not
+	// conflict with the other "/v1/users" routes.
+	HandlerProfile = HandlerIdentity{
+		Name: "profile",
+		URL:  "/v1/users/{id:[0-9]+}",
+	}
+
+	// HandlerMyProfile denotes HTTP handler for user to
+	// interact with their own profile data.
+	HandlerMyProfile = HandlerIdentity{
+		Name: "my-profile",
+		URL:  "/v1/users/me",
+	}
+
+	// HandlerMyPassword denotes HTTP handler for user to
+	// interact with their own password data.
+	HandlerMyPassword = HandlerIdentity{
+		Name: "my-password",
+		URL:  "/v1/user/me/password",
 	}
 )
 
@@ -109,16 +177,55 @@ func (h *Handler) createHTTPHandler(configName string) (http.Handler, error) {
 		httpHandler = &tokenHandler{
 			user: h.user,
 		}
+	case HandlerPasswordForgot.Name:
+		httpHandler = &passwordForgotHandler{
+			user: h.user,
+		}
+	case HandlerPasswordReset.Name:
+		httpHandler = &passwordResetHandler{
+			user: h.user,
+		}
+	case HandlerEmailVerification.Name:
+		httpHandler = &emailVerificationHandler{
+			user: h.user,
+		}
+	case HandlerEmailVerificationResend.Name:
+		httpHandler = &emailVerificationResendHandler{
+			user: h.user,
+		}
+	case HandlerLogout.Name:
+		httpHandler = &logoutHandler{
+			user: h.user,
+		}
+	case HandlerProfile.Name:
+		httpHandler = &profileHandler{
+			user: h.user,
+		}
+	case HandlerMyProfile.Name:
+		httpHandler = &profileHandler{
+			user: h.user,
+			self: true,
+		}
+	case HandlerMyPassword.Name:
+		httpHandler = &passwordHandler{
+			user: h.user,
+			self: true,
+		}
 	default:
 		return httpHandler, errUnknownConfig
 	}
 	return httpHandler, nil
 }
 
-// Start starts all HTTP handlers.
-func (h *Handler) Start(multiplexer *mux.Router) error {
+// Start starts all HTTP handlers. Handlers that are not
+// public are wrapped with the given authenticate middleware.
+func (h *Handler) Start(multiplexer *mux.Router, authenticate mux.MiddlewareFunc) error {
 	for _, handler := range h.handlers {
-		multiplexer.Handle(handler.identity.URL, handler.h)
+		if handler.identity.Public {
+			multiplexer.Handle(handler.identity.URL, handler.h)
+			continue
+		}
+		multiplexer.Handle(handler.identity.URL, authenticate(handler.h))
 	}
 	return nil
 }
@@ -130,3 +237,16 @@ type userHTTP struct {
 	Password    *string `json:"password"`
 	PhoneNumber *string `json:"phone_number"`
 }
+
+// profileHTTP is the user profile in HTTP response, it never
+// includes the password.
+type profileHTTP struct {
+	ID            *int64     `json:"id"`
+	Email         *string    `json:"email"`
+	EmailVerified *bool      `json:"email_verified"`
+	Name          *string    `json:"name"`
+	PhoneNumber   *string    `json:"phone_number"`
+	Role          *string    `json:"role"`
+	CreateTime    *time.Time `json:"create_time"`
+	UpdateTime    *time.Time `json:"update_time"`
+}
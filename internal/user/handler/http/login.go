@@ -26,7 +26,7 @@ type loginRequestData struct {
 type loginResponseData struct {
 	UserID int64  `json:"user_id"`
 	Email  string `json:"email"`
-	Token  string `json:"token"`
+	tokenResponseData
 }
 
 func (h *loginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -85,7 +85,7 @@ func (h *loginHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// login
-		token, tokenData, err := h.user.LoginBasic(ctx, data.Email, data.Password)
+		token, tokenData, err := h.user.LoginBasic(ctx, data.Email, data.Password, helper.GetClientIP(r))
 		if err != nil {
 			// determine error and status code, by default its internal error
 			parsedErr := errInternalServer
@@ -95,6 +95,19 @@ func (h *loginHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 				statusCode = http.StatusBadRequest
 			}
 
+			// the user has to verify the email first
+			if err == user.ErrEmailNotVerified {
+				statusCode = http.StatusForbidden
+			}
+
+			// login is attempted too soon or too many times
+			if err == user.ErrTooManyRequests {
+				statusCode = http.StatusTooManyRequests
+			}
+			if err == user.ErrAccountLocked {
+				statusCode = http.StatusLocked
+			}
+
 			// log the actual error if its internal error
 			if statusCode == http.StatusInternalServerError {
 				log.Printf("[User HTTP][handleLogin] Internal error from LoginBasic. Err: %s\n", err.Error())
@@ -105,9 +118,9 @@ func (h *loginHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		}
 
 		resChan <- loginResponseData{
-			UserID: tokenData.UserID,
-			Email:  tokenData.Email,
-			Token:  token,
+			UserID:            tokenData.UserID,
+			Email:             tokenData.Email,
+			tokenResponseData: formatTokenResponseData(token),
 		}
 	}()
 
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
+	"github.com/synapsis-test/internal/user"
+)
+
+type logoutHandler struct {
+	user user.Service
+}
+
+// logoutRequestData is the data from user to perform logout.
+type logoutRequestData struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (h *logoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// handle based on HTTP request method
+	switch r.Method {
+	case http.MethodPost:
+		h.handleLogout(w, r)
+	default:
+		helper.WriteErrorResponse(w, http.StatusMethodNotAllowed, []string{errMethodNotAllowed.Error()})
+	}
+}
+
+func (h *logoutHandler) handleLogout(w http.ResponseWriter, r *http.Request) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 3000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[User HTTP][handleLogout] Failed to logout. Err: %s\n", err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan int64, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// check access of the authenticated user
+		tokenData, err := auth.Authorize(ctx)
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// get token from header, it is the one to revoke
+		token, err := helper.GetBearerTokenFromHeader(r)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errInvalidToken
+			return
+		}
+
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		// unmarshall body, refresh token is optional
+		var data logoutRequestData
+		if len(body) > 0 {
+			err = json.Unmarshal(body, &data)
+			if err != nil {
+				statusCode = http.StatusBadRequest
+				errChan <- errBadRequest
+				return
+			}
+		}
+
+		// logout
+		err = h.user.Logout(ctx, token, data.RefreshToken)
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[User HTTP][handleLogout] Internal error from Logout. userID: %d. Err: %s\n", tokenData.UserID, err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- tokenData.UserID
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case userID := <-resChan:
+		res := helper.ResponseEnvelope{
+			Data: userID,
+		}
+		resBody, err = json.Marshal(res)
+	}
+}
@@ -10,12 +10,14 @@ import (
 	"time"
 
 	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
 	"github.com/synapsis-test/internal/user"
 	"github.com/gorilla/mux"
 )
 
 type passwordHandler struct {
 	user user.Service
+	self bool // serves the caller
 }
 
 type passwordRequesData struct {
@@ -24,12 +26,18 @@ type passwordRequesData struct {
 }
 
 func (h *passwordHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	userID, err := strconv.ParseInt(vars["id"], 10, 64)
-	if err != nil {
-		log.Printf("[User HTTP][passwordHandler] Failed to parse user ID. ID: %s. Err: %s\n", vars["id"], err.Error())
-		helper.WriteErrorResponse(w, http.StatusBadRequest, []string{errInvalidUserID.Error()})
-		return
+	// user ID of the caller is known after the token is
+	// checked, leave it empty for now
+	var userID int64
+	if !h.self {
+		vars := mux.Vars(r)
+		var err error
+		userID, err = strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			log.Printf("[User HTTP][passwordHandler] Failed to parse user ID. ID: %s. Err: %s\n", vars["id"], err.Error())
+			helper.WriteErrorResponse(w, http.StatusBadRequest, []string{errInvalidUserID.Error()})
+			return
+		}
 	}
 
 	// handle based on HTTP request method
@@ -72,14 +80,6 @@ func (h *passwordHandler) handleUpdatePassword(w http.ResponseWriter, r *http.Re
 	errChan := make(chan error, 1)
 
 	go func() {
-		// get token from header
-		token, err := helper.GetBearerTokenFromHeader(r)
-		if err != nil {
-			statusCode = http.StatusBadRequest
-			errChan <- errInvalidToken
-			return
-		}
-
 		// read request body
 		body, err := ioutil.ReadAll(r.Body)
 		if err != nil {
@@ -97,18 +97,30 @@ func (h *passwordHandler) handleUpdatePassword(w http.ResponseWriter, r *http.Re
 			return
 		}
 
-		// check access token
-		err = checkAccessToken(ctx, h.user, token, "handleUpdatePassword", userID)
+		// check access of the authenticated user
+		tokenData, err := auth.Authorize(ctx)
 		if err != nil {
-			statusCode = http.StatusUnauthorized
-			errChan <- err
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
 			return
 		}
 
-		// TODO: add authorization flow with roles
+		// resolve the user, it is the caller on "me" routes
+		ownerID := userID
+		if h.self {
+			ownerID = tokenData.UserID
+		}
+
+		// only the owner can update the password
+		err = user.Authorize(tokenData, user.RequireOwner(ownerID))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
 
 		// update password
-		err = h.user.UpdatePassword(ctx, userID, data.NewPassword, data.CurrentPassword)
+		err = h.user.UpdatePassword(ctx, ownerID, data.NewPassword, data.CurrentPassword)
 		if err != nil {
 			// determine error and status code, by default its internal error
 			parsedErr := errInternalServer
@@ -127,7 +139,7 @@ func (h *passwordHandler) handleUpdatePassword(w http.ResponseWriter, r *http.Re
 			return
 		}
 
-		resChan <- userID
+		resChan <- ownerID
 	}()
 
 	// wait and handle main go routine
@@ -172,14 +184,6 @@ func (h *passwordHandler) handleResetPassword(w http.ResponseWriter, r *http.Req
 	errChan := make(chan error, 1)
 
 	go func() {
-		// get token from header
-		token, err := helper.GetBearerTokenFromHeader(r)
-		if err != nil {
-			statusCode = http.StatusBadRequest
-			errChan <- errInvalidToken
-			return
-		}
-
 		// read request body
 		body, err := ioutil.ReadAll(r.Body)
 		if err != nil {
@@ -197,18 +201,22 @@ func (h *passwordHandler) handleResetPassword(w http.ResponseWriter, r *http.Req
 			return
 		}
 
-		// check access token
-		err = checkAccessToken(ctx, h.user, token, "handleResetPassword", userID)
+		// check access of the authenticated user
+		tokenData, err := auth.Authorize(ctx, user.RequireRole(user.RoleAdmin))
 		if err != nil {
-			statusCode = http.StatusUnauthorized
-			errChan <- err
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
 			return
 		}
 
-		// TODO: add authorization flow with roles
+		// resolve the user, it is the caller on "me" routes
+		ownerID := userID
+		if h.self {
+			ownerID = tokenData.UserID
+		}
 
 		// update password
-		err = h.user.ResetPassword(ctx, userID, data.NewPassword)
+		err = h.user.ResetPassword(ctx, ownerID, data.NewPassword)
 		if err != nil {
 			// determine error and status code, by default its internal error
 			parsedErr := errInternalServer
@@ -227,7 +235,7 @@ func (h *passwordHandler) handleResetPassword(w http.ResponseWriter, r *http.Req
 			return
 		}
 
-		resChan <- userID
+		resChan <- ownerID
 	}()
 
 	// wait and handle main go routine
@@ -0,0 +1,302 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/synapsis-test/global/helper"
+	"github.com/synapsis-test/internal/auth"
+	"github.com/synapsis-test/internal/user"
+)
+
+type profileHandler struct {
+	user user.Service
+	self bool // serves the caller
+}
+
+func (h *profileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// user ID of the caller is known after the token is
+	// checked, leave it empty for now
+	var userID int64
+	if !h.self {
+		vars := mux.Vars(r)
+		var err error
+		userID, err = strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			log.Printf("[User HTTP][profileHandler] Failed to parse user ID. ID: %s. Err: %s\n", vars["id"], err.Error())
+			helper.WriteErrorResponse(w, http.StatusBadRequest, []string{errInvalidUserID.Error()})
+			return
+		}
+	}
+
+	// handle based on HTTP request method
+	switch r.Method {
+	case http.MethodGet:
+		h.handleGetProfile(w, r, userID)
+	case http.MethodPatch:
+		h.handleUpdateProfile(w, r, userID)
+	default:
+		helper.WriteErrorResponse(w, http.StatusMethodNotAllowed, []string{errMethodNotAllowed.Error()})
+	}
+}
+
+func (h *profileHandler) handleGetProfile(w http.ResponseWriter, r *http.Request, userID int64) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 1000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[User HTTP][handleGetProfile] Failed to get profile. userID: %d, Err: %s\n", userID, err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan user.User, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// check access of the authenticated user
+		tokenData, err := auth.Authorize(ctx)
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// resolve the user, it is the caller on "me" routes
+		ownerID := userID
+		if h.self {
+			ownerID = tokenData.UserID
+		}
+
+		// only the owner or an admin can see the profile
+		err = user.Authorize(tokenData, user.RequireOwnerOrAdmin(ownerID))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		res, err := h.user.GetUserByID(ctx, ownerID)
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[User HTTP][handleGetProfile] Internal error from GetUserByID. Err: %s\n", err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- res
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case res := <-resChan:
+		resBody, err = json.Marshal(helper.ResponseEnvelope{
+			Status: "Success",
+			Data:   formatProfile(res),
+		})
+	}
+}
+
+func (h *profileHandler) handleUpdateProfile(w http.ResponseWriter, r *http.Request, userID int64) {
+	// add timeout to context
+	ctx, cancel := context.WithTimeout(r.Context(), 3000*time.Millisecond)
+	defer cancel()
+
+	var (
+		err        error           // stores error in this handler
+		resBody    []byte          // stores response body to write
+		statusCode = http.StatusOK // stores response status code
+	)
+
+	// write response
+	defer func() {
+		// error
+		if err != nil {
+			log.Printf("[User HTTP][handleUpdateProfile] Failed to update profile. userID: %d, Err: %s\n", userID, err.Error())
+			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
+			return
+		}
+		// success
+		helper.WriteResponse(w, resBody, statusCode, helper.JSONContentTypeDecorator)
+	}()
+
+	// prepare channels for main go routine
+	resChan := make(chan user.User, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		// unmarshall body
+		request := userHTTP{}
+		err = json.Unmarshal(body, &request)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		// password has its own handler
+		if request.Password != nil {
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
+			return
+		}
+
+		// check access of the authenticated user
+		tokenData, err := auth.Authorize(ctx)
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// resolve the user, it is the caller on "me" routes
+		ownerID := userID
+		if h.self {
+			ownerID = tokenData.UserID
+		}
+
+		// only the owner or an admin can update the profile
+		err = user.Authorize(tokenData, user.RequireOwnerOrAdmin(ownerID))
+		if err != nil {
+			statusCode = http.StatusForbidden
+			errChan <- errForbiddenAccess
+			return
+		}
+
+		// format HTTP request into service object
+		reqUser := parseUserFromUpdateProfileRequest(request)
+		reqUser.ID = ownerID
+
+		// request the email change first, so the profile is
+		// not updated if the new email is refused, the new
+		// email is only used once it is verified
+		if request.Email != nil {
+			err = h.user.ChangeEmail(ctx, ownerID, *request.Email)
+		}
+
+		var res user.User
+		if err == nil {
+			res, err = h.user.UpdateProfile(ctx, reqUser)
+		}
+
+		if err != nil {
+			// determine error and status code, by default its internal error
+			parsedErr := errInternalServer
+			statusCode = http.StatusInternalServerError
+			if v, ok := mapHTTPError[err]; ok {
+				parsedErr = v
+				statusCode = http.StatusBadRequest
+			}
+
+			// email change requested too soon
+			if err == user.ErrTooManyRequests {
+				statusCode = http.StatusTooManyRequests
+			}
+
+			// log the actual error if its internal error
+			if statusCode == http.StatusInternalServerError {
+				log.Printf("[User HTTP][handleUpdateProfile] Internal error from UpdateProfile or ChangeEmail. Err: %s\n", err.Error())
+			}
+
+			errChan <- parsedErr
+			return
+		}
+
+		resChan <- res
+	}()
+
+	// wait and handle main go routine
+	select {
+	case <-ctx.Done():
+		statusCode = http.StatusGatewayTimeout
+		err = errRequestTimeout
+	case err = <-errChan:
+	case res := <-resChan:
+		resBody, err = json.Marshal(helper.ResponseEnvelope{
+			Status: "Success",
+			Data:   formatProfile(res),
+		})
+	}
+}
+
+// parseUserFromUpdateProfileRequest returns user from the
+// given HTTP request object. The email is not included as
+// it is changed separately.
+func parseUserFromUpdateProfileRequest(uh userHTTP) user.User {
+	result := user.User{}
+
+	if uh.Name != nil {
+		result.Name = *uh.Name
+	}
+
+	if uh.PhoneNumber != nil {
+		result.PhoneNumber = *uh.PhoneNumber
+	}
+
+	return result
+}
+
+// formatProfile formats the given user into the respective
+// HTTP-format object. The password is never included.
+func formatProfile(u user.User) profileHTTP {
+	role := u.Role.String()
+
+	var updateTime *time.Time
+	if !u.UpdateTime.IsZero() {
+		updateTime = &u.UpdateTime
+	}
+
+	return profileHTTP{
+		ID:            &u.ID,
+		Email:         &u.Email,
+		EmailVerified: &u.EmailVerified,
+		Name:          &u.Name,
+		PhoneNumber:   &u.PhoneNumber,
+		Role:          &role,
+		CreateTime:    &u.CreateTime,
+		UpdateTime:    updateTime,
+	}
+}
@@ -3,39 +3,42 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"io/ioutil"
 	"log"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/synapsis-test/global/helper"
 	"github.com/synapsis-test/internal/user"
-	"github.com/gorilla/mux"
 )
 
 type tokenHandler struct {
 	user user.Service
 }
 
-func (h *tokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	userID, err := strconv.ParseInt(vars["id"], 10, 64)
-	if err != nil {
-		log.Printf("[User HTTP][tokenHandler] Failed to parse user ID. ID: %s. Err: %s\n", vars["id"], err.Error())
-		helper.WriteErrorResponse(w, http.StatusBadRequest, []string{errInvalidUserID.Error()})
-		return
-	}
+// tokenRequestData is the data from user to refresh token.
+type tokenRequestData struct {
+	RefreshToken string `json:"refresh_token"`
+}
 
+// tokenResponseData is the data to user after refresh token.
+type tokenResponseData struct {
+	Token           string    `json:"token"`
+	TokenExpireTime time.Time `json:"token_expire_time"`
+	RefreshToken    string    `json:"refresh_token"`
+}
+
+func (h *tokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// handle based on HTTP request method
 	switch r.Method {
 	case http.MethodPost:
-		h.handleRefreshToken(w, r, userID)
+		h.handleRefreshToken(w, r)
 	default:
 		helper.WriteErrorResponse(w, http.StatusMethodNotAllowed, []string{errMethodNotAllowed.Error()})
 	}
 }
 
-func (h *tokenHandler) handleRefreshToken(w http.ResponseWriter, r *http.Request, userID int64) {
+func (h *tokenHandler) handleRefreshToken(w http.ResponseWriter, r *http.Request) {
 	// add timeout to context
 	ctx, cancel := context.WithTimeout(r.Context(), 3000*time.Millisecond)
 	defer cancel()
@@ -49,6 +52,7 @@ func (h *tokenHandler) handleRefreshToken(w http.ResponseWriter, r *http.Request
 
 	// write response
 	defer func() {
+		// error
 		if err != nil {
 			log.Printf("[User HTTP][handleRefreshToken] Failed to refresh token. Source: %s, Err: %s\n", source, err.Error())
 			helper.WriteErrorResponse(w, statusCode, []string{err.Error()})
@@ -59,30 +63,29 @@ func (h *tokenHandler) handleRefreshToken(w http.ResponseWriter, r *http.Request
 	}()
 
 	// prepare channels for main go routine
-	resChan := make(chan string, 1)
+	resChan := make(chan tokenResponseData, 1)
 	errChan := make(chan error, 1)
 
 	go func() {
-		// get token from header
-		token, err := helper.GetBearerTokenFromHeader(r)
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
 		if err != nil {
 			statusCode = http.StatusBadRequest
-			errChan <- errInvalidToken
+			errChan <- errBadRequest
 			return
 		}
 
-		// check access token
-		err = checkAccessToken(ctx, h.user, token, "handleRefreshToken", userID)
+		// unmarshall body
+		var data tokenRequestData
+		err = json.Unmarshal(body, &data)
 		if err != nil {
-			statusCode = http.StatusUnauthorized
-			errChan <- err
+			statusCode = http.StatusBadRequest
+			errChan <- errBadRequest
 			return
 		}
 
-		// TODO: add authorization flow with roles
-
 		// refresh token
-		newToken, err := h.user.RefreshToken(ctx, token)
+		token, err := h.user.RefreshToken(ctx, data.RefreshToken)
 		if err != nil {
 			// determine error and status code, by default its internal error
 			parsedErr := errInternalServer
@@ -101,7 +104,7 @@ func (h *tokenHandler) handleRefreshToken(w http.ResponseWriter, r *http.Request
 			return
 		}
 
-		resChan <- newToken
+		resChan <- formatTokenResponseData(token)
 	}()
 
 	// wait and handle main go routine
@@ -110,10 +113,20 @@ func (h *tokenHandler) handleRefreshToken(w http.ResponseWriter, r *http.Request
 		statusCode = http.StatusGatewayTimeout
 		err = errRequestTimeout
 	case err = <-errChan:
-	case token := <-resChan:
+	case resData := <-resChan:
 		res := helper.ResponseEnvelope{
-			Data: token,
+			Data: resData,
 		}
 		resBody, err = json.Marshal(res)
 	}
 }
+
+// formatTokenResponseData formats the given token into
+// response data.
+func formatTokenResponseData(token user.Token) tokenResponseData {
+	return tokenResponseData{
+		Token:           token.AccessToken,
+		TokenExpireTime: token.AccessTokenExpireTime,
+		RefreshToken:    token.RefreshToken,
+	}
+}
@@ -0,0 +1,63 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/synapsis-test/internal/user/mailer"
+)
+
+// client implements mailer.Mailer by appending every mail to
+// a file instead of sending it, or writing it to the log if
+// there is no file.
+//
+// It is suitable for local development.
+type client struct {
+	mu   sync.Mutex
+	path string
+}
+
+// New creates a new file mailer that writes mails to the
+// file in the given path. Mails are written to the log if the
+// given path is empty.
+func New(path string) (*client, error) {
+	c := &client{
+		path: path,
+	}
+
+	// make sure the file is writable
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+		f.Close()
+	}
+
+	return c, nil
+}
+
+func (c *client) Send(ctx context.Context, mail mailer.Mail) error {
+	content := fmt.Sprintf("Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC1123Z), mail.To, mail.Subject, mail.Body)
+
+	if c.path == "" {
+		log.Printf("[File Mailer][Send] Mail is not sent.\n%s", content)
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(content)
+	return err
+}
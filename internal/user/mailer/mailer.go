@@ -0,0 +1,16 @@
+package mailer
+
+import "context"
+
+// Mailer sends mails to users.
+type Mailer interface {
+	// Send sends the given mail.
+	Send(ctx context.Context, mail Mail) error
+}
+
+// Mail denotes a plain text mail.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
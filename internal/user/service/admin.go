@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"github.com/synapsis-test/internal/user"
+)
+
+// EnsureAdmin makes sure the user with the email of the
+// given user is an admin.
+func (s *service) EnsureAdmin(ctx context.Context, admin user.User) (int64, error) {
+	// get pg store client without using transaction
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return 0, err
+	}
+
+	// create the admin if it does not exist yet
+	current, err := pgStoreClient.GetUserByEmail(ctx, admin.Email)
+	if err == user.ErrDataNotFound {
+		admin.Role = user.RoleAdmin
+		admin.EmailVerified = true // configured by the operator
+		return s.CreateUser(ctx, admin)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// the existing user may be registered by anyone, so it
+	// is never promoted
+	if current.Role != user.RoleAdmin {
+		return 0, user.ErrAdminEmailTaken
+	}
+
+	return current.ID, nil
+}
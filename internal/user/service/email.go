@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+
+	"github.com/synapsis-test/internal/user"
+	"github.com/synapsis-test/internal/user/mailer"
+	"github.com/synapsis-test/internal/user/store/postgresql"
+)
+
+// emailVerificationTokenSize is the size in bytes of an
+// email verification token.
+const emailVerificationTokenSize = 32
+
+func (s *service) VerifyEmail(ctx context.Context, token string) error {
+	if token == "" {
+		return user.ErrInvalidToken
+	}
+
+	// get pg store client using transaction
+	pgStoreClient, err := s.pgStore.NewClient(true)
+	if err != nil {
+		return err
+	}
+
+	err = s.verifyEmail(ctx, pgStoreClient, token)
+	if err != nil {
+		pgStoreClient.Rollback()
+		return err
+	}
+
+	return pgStoreClient.Commit()
+}
+
+func (s *service) ResendEmailVerification(ctx context.Context, email string) error {
+	// validate the given values
+	if email == "" {
+		return user.ErrInvalidEmail
+	}
+
+	// limit the rate before anything else, so it does not
+	// tell whether the email exists
+	allowed, err := s.redisClient.SetNX(ctx, formatEmailVerificationResendRedisKey(email), 1, emailVerificationResendInterval).Result()
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return user.ErrTooManyRequests
+	}
+
+	// get pg store client without using transaction
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return err
+	}
+
+	// get user current data, do not tell if there is none
+	current, err := pgStoreClient.GetUserByEmail(ctx, email)
+	if err == user.ErrDataNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if current.EmailVerified {
+		return nil
+	}
+
+	return s.sendEmailVerification(ctx, pgStoreClient, current)
+}
+
+func (s *service) ChangeEmail(ctx context.Context, userID int64, newEmail string) error {
+	// validate the given values
+	if userID <= 0 {
+		return user.ErrInvalidUserID
+	}
+	_, err := mail.ParseAddress(newEmail)
+	if newEmail == "" || err != nil {
+		return user.ErrInvalidEmail
+	}
+
+	// get pg store client without using transaction
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return err
+	}
+
+	// get user current data
+	current, err := pgStoreClient.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// nothing to change
+	if current.Email == newEmail {
+		return nil
+	}
+
+	// the email is used by other user
+	_, err = pgStoreClient.GetUserByEmail(ctx, newEmail)
+	if err == nil {
+		return user.ErrUserAlreadyExist
+	}
+	if err != user.ErrDataNotFound {
+		return err
+	}
+
+	// limit the rate, so it can not be used to flood an email
+	allowed, err := s.redisClient.SetNX(ctx, formatEmailVerificationResendRedisKey(newEmail), 1, emailVerificationResendInterval).Result()
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return user.ErrTooManyRequests
+	}
+
+	// the email is changed once the token sent to the new
+	// email is verified
+	current.Email = newEmail
+	return s.sendEmailVerification(ctx, pgStoreClient, current)
+}
+
+// verifyEmail uses the given email verification token and
+// marks the email it is sent to as verified using the given
+// pg store client, which should use transaction. If the
+// email is not the current email of the user, the email of
+// the user is changed to it.
+func (s *service) verifyEmail(ctx context.Context, pgStoreClient postgresql.PGStoreClient, token string) error {
+	now := s.timeNow()
+
+	// use the token, it can not be used again from now
+	verification, err := pgStoreClient.UseEmailVerificationToken(ctx, hashToken(token), now)
+	if err != nil {
+		return err
+	}
+
+	// get user current data
+	current, err := pgStoreClient.GetUserByIDForUpdate(ctx, verification.UserID)
+	if err != nil {
+		return err
+	}
+
+	// the token is sent to change the email, so the new email
+	// is verified and used from now
+	if current.Email != verification.Email {
+		current.Email = verification.Email
+		current.EmailVerified = true
+		current.UpdateTime = now
+
+		err = pgStoreClient.UpdateUser(ctx, current)
+		if err != nil {
+			return err
+		}
+
+		// the other tokens are sent to the previous email or
+		// to change to another email, they are obsolete now
+		return pgStoreClient.RevokeEmailVerificationTokens(ctx, current.ID, now)
+	}
+
+	if current.EmailVerified {
+		return nil
+	}
+
+	// update fields
+	current.EmailVerified = true
+	current.UpdateTime = now
+
+	return pgStoreClient.UpdateUserEmailVerified(ctx, current)
+}
+
+// sendEmailVerification stores a new email verification
+// token for the email of the given user using the given pg
+// store client, and mails it in background.
+func (s *service) sendEmailVerification(ctx context.Context, pgStoreClient postgresql.PGStoreClient, data user.User) error {
+	token, err := generateRandomToken(emailVerificationTokenSize)
+	if err != nil {
+		return err
+	}
+
+	// only the hash is stored, so a leaked store can not be
+	// used to verify email
+	now := s.timeNow()
+	_, err = pgStoreClient.CreateEmailVerificationToken(ctx, user.EmailVerificationToken{
+		UserID:     data.ID,
+		Email:      data.Email,
+		TokenHash:  hashToken(token),
+		ExpireTime: now.Add(s.config.EmailVerificationExpiration),
+		CreateTime: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	go s.sendMail(data.ID, mailer.Mail{
+		To:      data.Email,
+		Subject: "Verify your email",
+		Body:    fmt.Sprintf("Use the following token to verify your email. It expires in %s.\n\n%s", s.config.EmailVerificationExpiration, token),
+	})
+
+	return nil
+}
+
+// formatEmailVerificationResendRedisKey returns the redis
+// key to limit resending email verification to the given
+// email.
+func formatEmailVerificationResendRedisKey(email string) string {
+	return fmt.Sprintf("user:email-verification:resend:%s", email)
+}
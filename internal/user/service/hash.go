@@ -1,28 +1,245 @@
 package service
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
 
-// generateHash returns the generated has for the given text
-// with the given salt using Bcrypt.
-func generateHash(text string, salt string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(text), bcrypt.MinCost)
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Followings are the known password hash algorithms.
+const (
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashArgon2id = "argon2id"
+)
+
+// Followings are the known error returned from password
+// hasher.
+var (
+	errUnknownPasswordHash = errors.New("unknown password hash algorithm")
+)
+
+// PasswordHasher hashes passwords with an algorithm and its
+// parameters, and verifies passwords against the hashes.
+type PasswordHasher interface {
+	// Hash returns the hash of the given password.
+	Hash(password string) (string, error)
+
+	// Verify returns whether the given password matches the
+	// given hash. It returns false if the hash is not made
+	// with the algorithm of the hasher.
+	Verify(password string, hash string) (bool, error)
+
+	// NeedsRehash returns whether the given hash is made with
+	// other algorithm or parameters than the hasher's.
+	NeedsRehash(hash string) bool
+}
+
+// newPasswordHasher returns the password hasher of the given
+// algorithm configured by the given config.
+func newPasswordHasher(algorithm string, config Config) (PasswordHasher, error) {
+	switch algorithm {
+	case PasswordHashBcrypt:
+		return &bcryptHasher{
+			cost:   config.BcryptCost,
+			pepper: config.PasswordSalt,
+		}, nil
+	case PasswordHashArgon2id:
+		return &argon2idHasher{
+			memory:      config.Argon2idMemory,
+			iterations:  config.Argon2idIterations,
+			parallelism: config.Argon2idParallelism,
+			pepper:      config.PasswordSalt,
+		}, nil
+	default:
+		return nil, errUnknownPasswordHash
+	}
+}
+
+// newFallbackPasswordHashers returns every known password
+// hasher other than the given preferred algorithm, to verify
+// passwords hashed before the preferred one is used.
+func newFallbackPasswordHashers(preferred string, config Config) []PasswordHasher {
+	hashers := []PasswordHasher{}
+	for _, algorithm := range []string{PasswordHashArgon2id, PasswordHashBcrypt} {
+		if algorithm == preferred {
+			continue
+		}
+		hasher, _ := newPasswordHasher(algorithm, config)
+		hashers = append(hashers, hasher)
+	}
+
+	// passwords used to be hashed without pepper
+	return append(hashers, &bcryptHasher{
+		cost: bcrypt.MinCost,
+	})
+}
+
+// bcryptHasher implements PasswordHasher using Bcrypt.
+type bcryptHasher struct {
+	cost   int
+	pepper string
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(applyPepper(password, h.pepper)), h.cost)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 	return string(hash), nil
 }
 
-// compareHash compares the given text and the given hash
-// with the given salt using Bcrypt.
-func compareHash(text string, hash string, salt string) (bool, error) {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(text))
-	if err != nil && err != bcrypt.ErrMismatchedHashAndPassword {
+func (h *bcryptHasher) Verify(password string, hash string) (bool, error) {
+	if !isBcryptHash(hash) {
+		return false, nil
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(applyPepper(password, h.pepper)))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	if err != nil {
 		return false, err
 	}
 
+	return true, nil
+}
+
+func (h *bcryptHasher) NeedsRehash(hash string) bool {
+	if !isBcryptHash(hash) {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
+}
+
+// isBcryptHash returns whether the given hash is made with
+// Bcrypt.
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// Followings are the fixed parameters of Argon2id.
+const (
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+)
+
+// argon2idHasher implements PasswordHasher using Argon2id,
+// and formats the hash in PHC string format:
+//
+//	$argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+type argon2idHasher struct {
+	memory      uint32 // in KiB
+	iterations  uint32
+	parallelism uint8
+	pepper      string
+}
+
+// argon2idHash denotes parsed Argon2id PHC string.
+type argon2idHash struct {
+	version     int
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2idSaltLength)
+	_, err := rand.Read(salt)
 	if err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(applyPepper(password, h.pepper)), salt, h.iterations, h.memory, h.parallelism, argon2idKeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.memory, h.iterations, h.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *argon2idHasher) Verify(password string, hash string) (bool, error) {
+	parsed, ok := parseArgon2idHash(hash)
+	if !ok {
 		return false, nil
 	}
 
-	return true, nil
+	key := argon2.IDKey([]byte(applyPepper(password, h.pepper)), parsed.salt, parsed.iterations, parsed.memory, parsed.parallelism, uint32(len(parsed.key)))
+
+	return subtle.ConstantTimeCompare(key, parsed.key) == 1, nil
+}
+
+func (h *argon2idHasher) NeedsRehash(hash string) bool {
+	parsed, ok := parseArgon2idHash(hash)
+	if !ok {
+		return true
+	}
+
+	return parsed.version != argon2.Version ||
+		parsed.memory != h.memory ||
+		parsed.iterations != h.iterations ||
+		parsed.parallelism != h.parallelism ||
+		len(parsed.salt) != argon2idSaltLength ||
+		len(parsed.key) != argon2idKeyLength
+}
+
+// parseArgon2idHash parses the given Argon2id PHC string. It
+// returns false if the given hash is not an Argon2id hash.
+func parseArgon2idHash(hash string) (argon2idHash, bool) {
+	// "", "argon2id", "v=..", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != PasswordHashArgon2id {
+		return argon2idHash{}, false
+	}
+
+	var parsed argon2idHash
+	_, err := fmt.Sscanf(parts[2], "v=%d", &parsed.version)
+	if err != nil {
+		return argon2idHash{}, false
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &parsed.memory, &parsed.iterations, &parsed.parallelism)
+	if err != nil {
+		return argon2idHash{}, false
+	}
+
+	parsed.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2idHash{}, false
+	}
+
+	parsed.key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(parsed.key) == 0 {
+		return argon2idHash{}, false
+	}
+
+	return parsed, true
+}
+
+// applyPepper returns the given password keyed with the
+// given pepper using HMAC-SHA256, or the password as it is
+// if there is no pepper.
+//
+// The result is base64 encoded so it fits within the Bcrypt
+// 72 bytes limit regardless of the password length.
+func applyPepper(password string, pepper string) string {
+	if pepper == "" {
+		return password
+	}
+
+	mac := hmac.New(sha256.New, []byte(pepper))
+	mac.Write([]byte(password))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
 }
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Followings are the cheap parameters of the hashers tested,
+// so the tests run fast.
+const (
+	testBcryptCost          = bcrypt.MinCost
+	testArgon2idMemory      = 64
+	testArgon2idIterations  = 1
+	testArgon2idParallelism = 1
+	testPepper              = "pepper"
+)
+
+func newTestBcryptHasher() *bcryptHasher {
+	return &bcryptHasher{
+		cost:   testBcryptCost,
+		pepper: testPepper,
+	}
+}
+
+func newTestArgon2idHasher() *argon2idHasher {
+	return &argon2idHasher{
+		memory:      testArgon2idMemory,
+		iterations:  testArgon2idIterations,
+		parallelism: testArgon2idParallelism,
+		pepper:      testPepper,
+	}
+}
+
+func TestPasswordHasherVerify(t *testing.T) {
+	hashers := map[string]PasswordHasher{
+		PasswordHashBcrypt:   newTestBcryptHasher(),
+		PasswordHashArgon2id: newTestArgon2idHasher(),
+	}
+
+	for name, hasher := range hashers {
+		t.Run(name, func(t *testing.T) {
+			hash, err := hasher.Hash("secret123")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+
+			match, err := hasher.Verify("secret123", hash)
+			if err != nil || !match {
+				t.Errorf("Verify() with the same password = %v, %v, want true, nil", match, err)
+			}
+
+			match, err = hasher.Verify("secret124", hash)
+			if err != nil || match {
+				t.Errorf("Verify() with other password = %v, %v, want false, nil", match, err)
+			}
+
+			if hasher.NeedsRehash(hash) {
+				t.Errorf("NeedsRehash() of its own hash = true, want false")
+			}
+		})
+	}
+}
+
+func TestPasswordHasherOtherAlgorithm(t *testing.T) {
+	bcryptHash, err := newTestBcryptHasher().Hash("secret123")
+	if err != nil {
+		t.Fatalf("bcrypt Hash() error = %v", err)
+	}
+	argon2idHash, err := newTestArgon2idHasher().Hash("secret123")
+	if err != nil {
+		t.Fatalf("argon2id Hash() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		hasher PasswordHasher
+		hash   string
+	}{
+		{name: "bcrypt verifies argon2id hash", hasher: newTestBcryptHasher(), hash: argon2idHash},
+		{name: "argon2id verifies bcrypt hash", hasher: newTestArgon2idHasher(), hash: bcryptHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := tt.hasher.Verify("secret123", tt.hash)
+			if err != nil || match {
+				t.Errorf("Verify() = %v, %v, want false, nil", match, err)
+			}
+
+			if !tt.hasher.NeedsRehash(tt.hash) {
+				t.Errorf("NeedsRehash() = false, want true")
+			}
+		})
+	}
+}
+
+func TestPasswordHasherPepper(t *testing.T) {
+	hash, err := newTestBcryptHasher().Hash("secret123")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	// passwords hashed before pepper is used only match
+	// without it
+	unpeppered := &bcryptHasher{cost: testBcryptCost}
+	match, err := unpeppered.Verify("secret123", hash)
+	if err != nil || match {
+		t.Errorf("Verify() without pepper = %v, %v, want false, nil", match, err)
+	}
+}
+
+func TestBcryptHasherNeedsRehash(t *testing.T) {
+	hash, err := newTestBcryptHasher().Hash("secret123")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	hasher := newTestBcryptHasher()
+	hasher.cost = testBcryptCost + 1
+	if !hasher.NeedsRehash(hash) {
+		t.Errorf("NeedsRehash() with other cost = false, want true")
+	}
+}
+
+func TestArgon2idHasherNeedsRehash(t *testing.T) {
+	hash, err := newTestArgon2idHasher().Hash("secret123")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(h *argon2idHasher)
+	}{
+		{name: "other memory", modify: func(h *argon2idHasher) { h.memory *= 2 }},
+		{name: "other iterations", modify: func(h *argon2idHasher) { h.iterations++ }},
+		{name: "other parallelism", modify: func(h *argon2idHasher) { h.parallelism++ }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher := newTestArgon2idHasher()
+			tt.modify(hasher)
+
+			if !hasher.NeedsRehash(hash) {
+				t.Errorf("NeedsRehash() = false, want true")
+			}
+
+			// the hash is still verified with its own parameters
+			match, err := hasher.Verify("secret123", hash)
+			if err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+			}
+		})
+	}
+}
+
+func TestParseArgon2idHash(t *testing.T) {
+	tests := []struct {
+		name string
+		hash string
+		want argon2idHash
+		ok   bool
+	}{
+		{
+			name: "valid",
+			hash: "$argon2id$v=19$m=65536,t=3,p=2$c29tZXNhbHQ$a2V5",
+			want: argon2idHash{
+				version:     argon2.Version,
+				memory:      65536,
+				iterations:  3,
+				parallelism: 2,
+				salt:        []byte("somesalt"),
+				key:         []byte("key"),
+			},
+			ok: true,
+		},
+		{name: "empty", hash: "", ok: false},
+		{name: "bcrypt", hash: "$2a$10$abcdefghijklmnopqrstuu5Jm8p0dZ9h3yQzE7J3qv1Yk3u2xW6a", ok: false},
+		{name: "argon2i", hash: "$argon2i$v=19$m=65536,t=3,p=2$c29tZXNhbHQ$a2V5", ok: false},
+		{name: "missing part", hash: "$argon2id$v=19$m=65536,t=3,p=2$c29tZXNhbHQ", ok: false},
+		{name: "invalid version", hash: "$argon2id$v=x$m=65536,t=3,p=2$c29tZXNhbHQ$a2V5", ok: false},
+		{name: "invalid parameters", hash: "$argon2id$v=19$m=65536,t=3$c29tZXNhbHQ$a2V5", ok: false},
+		{name: "invalid salt", hash: "$argon2id$v=19$m=65536,t=3,p=2$!!!$a2V5", ok: false},
+		{name: "empty key", hash: "$argon2id$v=19$m=65536,t=3,p=2$c29tZXNhbHQ$", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseArgon2idHash(tt.hash)
+			if ok != tt.ok {
+				t.Fatalf("parseArgon2idHash() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+
+			if got.version != tt.want.version ||
+				got.memory != tt.want.memory ||
+				got.iterations != tt.want.iterations ||
+				got.parallelism != tt.want.parallelism ||
+				string(got.salt) != string(tt.want.salt) ||
+				string(got.key) != string(tt.want.key) {
+				t.Errorf("parseArgon2idHash() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgon2idHasherHashFormat(t *testing.T) {
+	hash, err := newTestArgon2idHasher().Hash("secret123")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("Hash() = %s, want PHC string with the hasher parameters", hash)
+	}
+
+	parsed, ok := parseArgon2idHash(hash)
+	if !ok {
+		t.Fatalf("parseArgon2idHash() of the hash ok = false, want true")
+	}
+	if len(parsed.salt) != argon2idSaltLength || len(parsed.key) != argon2idKeyLength {
+		t.Errorf("parsed salt and key length = %d, %d, want %d, %d", len(parsed.salt), len(parsed.key), argon2idSaltLength, argon2idKeyLength)
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/synapsis-test/internal/user"
+)
+
+// Followings are the fields of the redis hash that stores
+// failed logins of an email.
+const (
+	loginFieldFailures  = "failures"
+	loginFieldRetryTime = "retry_time" // unix nano
+	loginFieldLockTime  = "lock_time"  // unix nano, the lock expires at
+)
+
+// releaseLoginIPScript takes back a login counted for an IP
+// address, unless the counter is already expired, so it is
+// not recreated without TTL.
+var releaseLoginIPScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return redis.call("DECR", KEYS[1])
+end
+return 0
+`)
+
+// claimLoginAttempt checks whether a login for the given
+// email from the given IP address can be attempted now, and
+// counts it as failed until resetLoginFailures is called.
+// Counting it before the password is checked makes sure
+// concurrent logins can not get past the limits.
+//
+// It returns user.ErrTooManyRequests if the login is
+// attempted too soon or the IP address fails too many
+// times, or user.ErrAccountLocked if the email is locked.
+func (s *service) claimLoginAttempt(ctx context.Context, email string, ip string) error {
+	// refuse the IP address that fails too many times
+	if ip != "" {
+		ipKey := formatLoginIPRedisKey(ip)
+
+		pipe := s.redisClient.TxPipeline()
+		incr := pipe.Incr(ctx, ipKey)
+		pipe.Expire(ctx, ipKey, s.config.LoginFailureWindow)
+		_, err := pipe.Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		if incr.Val() > int64(s.config.MaxLoginFailuresPerIP) {
+			if incr.Val() == int64(s.config.MaxLoginFailuresPerIP)+1 {
+				log.Printf("[User Service][LoginBasic] IP address is refused. ip: %s, failures: %d\n", ip, s.config.MaxLoginFailuresPerIP)
+			}
+			return user.ErrTooManyRequests
+		}
+	}
+
+	// the email is watched, so a concurrent login for it
+	// makes the transaction fail instead of being missed
+	emailKey := formatLoginEmailRedisKey(email)
+	err := s.redisClient.Watch(ctx, func(tx *redis.Tx) error {
+		attempt, err := tx.HGetAll(ctx, emailKey).Result()
+		if err != nil {
+			return err
+		}
+
+		now := s.timeNow()
+		failures, _ := strconv.ParseInt(attempt[loginFieldFailures], 10, 64)
+
+		lockTime := parseUnixNano(attempt[loginFieldLockTime])
+		if !lockTime.IsZero() {
+			if now.Before(lockTime) {
+				return user.ErrAccountLocked
+			}
+
+			// the lock is expired, start over
+			log.Printf("[User Service][LoginBasic] Account is unlocked. email: %s\n", email)
+			failures = 0
+		} else if now.Before(parseUnixNano(attempt[loginFieldRetryTime])) {
+			return user.ErrTooManyRequests
+		}
+
+		// delay the next login, or lock if it fails too many times
+		failures++
+		field := loginFieldRetryTime
+		until := now.Add(loginBackoff(failures))
+		ttl := s.config.LoginFailureWindow
+		if failures >= int64(s.config.MaxLoginFailures) {
+			field = loginFieldLockTime
+			until = now.Add(s.config.LoginLockoutDuration)
+			ttl += s.config.LoginLockoutDuration
+			log.Printf("[User Service][LoginBasic] Account is locked unless the login succeeds. email: %s, failures: %d, until: %s\n", email, failures, until.Format(time.RFC3339))
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, emailKey)
+			pipe.HSet(ctx, emailKey, loginFieldFailures, failures, field, until.UnixNano())
+			pipe.Expire(ctx, emailKey, ttl)
+			return nil
+		})
+		return err
+	}, emailKey)
+	if err == redis.TxFailedErr {
+		return user.ErrTooManyRequests
+	}
+
+	return err
+}
+
+// resetLoginFailures forgets failed logins of the given
+// email, and takes back the login counted for the given IP
+// address. Failed logins of the IP address are kept until
+// they expire, so a successful login can not clear them.
+func (s *service) resetLoginFailures(ctx context.Context, email string, ip string) error {
+	err := s.redisClient.Del(ctx, formatLoginEmailRedisKey(email)).Err()
+	if err != nil {
+		return err
+	}
+
+	if ip == "" {
+		return nil
+	}
+	return releaseLoginIPScript.Run(ctx, s.redisClient, []string{formatLoginIPRedisKey(ip)}).Err()
+}
+
+// loginBackoff returns the delay before the next login after
+// the given number of consecutive failures.
+func loginBackoff(failures int64) time.Duration {
+	backoff := loginBackoffBase
+	for i := int64(1); i < failures && backoff < loginBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > loginBackoffMax {
+		backoff = loginBackoffMax
+	}
+	return backoff
+}
+
+// parseUnixNano returns time of the given unix nano string,
+// or zero time if it is empty or invalid.
+func parseUnixNano(value string) time.Time {
+	nano, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || nano <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nano)
+}
+
+// formatLoginEmailRedisKey returns the redis key to store
+// failed logins of the given email.
+func formatLoginEmailRedisKey(email string) string {
+	return fmt.Sprintf("user:login:email:%s", strings.ToLower(email))
+}
+
+// formatLoginIPRedisKey returns the redis key to count
+// failed logins from the given IP address.
+func formatLoginIPRedisKey(ip string) string {
+	return fmt.Sprintf("user:login:ip:%s", ip)
+}
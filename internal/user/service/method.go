@@ -2,12 +2,17 @@ package service
 
 import (
 	"context"
+	"log"
 	"net/mail"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/synapsis-test/internal/user"
+	"github.com/synapsis-test/internal/user/store/postgresql"
 )
 
-// CreateUser create new user as given.
+// CreateUser create new user as given, and mails a token
+// to verify the email of the user unless it is verified.
 // CreateUser expects the given  user ID in the given
 // user already assigned.
 func (s *service) CreateUser(ctx context.Context, reqUser user.User) (int64, error) {
@@ -16,15 +21,22 @@ func (s *service) CreateUser(ctx context.Context, reqUser user.User) (int64, err
 	if err != nil {
 		return 0, err
 	}
+	err = validatePassword(reqUser.Password)
+	if err != nil {
+		return 0, err
+	}
 
 	// hash password
-	hash, err := generateHash(reqUser.Password, s.config.PasswordSalt)
+	hash, err := s.passwordHasher.Hash(reqUser.Password)
 	if err != nil {
 		return 0, err
 	}
 
-	// modify fields
+	// modify fields, user is a customer unless stated otherwise
 	reqUser.Password = hash
+	if reqUser.Role == user.RoleUnknown {
+		reqUser.Role = user.RoleCustomer
+	}
 	reqUser.CreateTime = s.timeNow()
 
 	// get pg store client using transaction
@@ -39,52 +51,103 @@ func (s *service) CreateUser(ctx context.Context, reqUser user.User) (int64, err
 		return 0, err
 	}
 
+	// the user is already created, so only log it, the
+	// verification can be resent
+	if !reqUser.EmailVerified {
+		reqUser.ID = userID
+		err = s.sendEmailVerification(ctx, pgStoreClient, reqUser)
+		if err != nil {
+			log.Printf("[User Service][CreateUser] Failed to send email verification. userID: %d. Err: %s\n", userID, err.Error())
+		}
+	}
+
 	return userID, nil
 }
 
-// UpdatePassword, for the given user ID, updates user's
-// password with the new password. Before updating, it
-// checks whether the current password are correct.
-func (s *service) UpdatePassword(ctx context.Context, userID int64, newPassword string, currentPassword string) error {
-	if newPassword == "" || currentPassword == "" {
-		return user.ErrInvalidPassword
+// GetUserByID returns the user with the given user ID. The
+// password hash of the user is not returned.
+func (s *service) GetUserByID(ctx context.Context, userID int64) (user.User, error) {
+	// validate the given values
+	if userID <= 0 {
+		return user.User{}, user.ErrInvalidUserID
 	}
 
 	// get pg store client without using transaction
 	pgStoreClient, err := s.pgStore.NewClient(false)
 	if err != nil {
-		return err
+		return user.User{}, err
 	}
 
 	// get user current data
 	current, err := pgStoreClient.GetUserByID(ctx, userID)
 	if err != nil {
-		return err
+		return user.User{}, err
 	}
 
-	// check current password
-	err = s.checkPassword(ctx, current, currentPassword)
+	// never expose the password hash
+	current.Password = ""
+
+	return current, nil
+}
+
+// UpdateProfile updates name and phone number of the user
+// with the user ID of the given user, and returns the updated
+// user without the password hash. Empty fields in the given
+// user keep their current values.
+func (s *service) UpdateProfile(ctx context.Context, reqUser user.User) (user.User, error) {
+	// validate the given values
+	if reqUser.ID <= 0 {
+		return user.User{}, user.ErrInvalidUserID
+	}
+
+	// get pg store client using transaction
+	pgStoreClient, err := s.pgStore.NewClient(true)
 	if err != nil {
-		return err
+		return user.User{}, err
 	}
 
-	// hash password
-	hash, err := generateHash(newPassword, s.config.PasswordSalt)
+	updated, err := s.updateProfile(ctx, pgStoreClient, reqUser)
+	if err != nil {
+		pgStoreClient.Rollback()
+		return user.User{}, err
+	}
+
+	err = pgStoreClient.Commit()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	// never expose the password hash
+	updated.Password = ""
+
+	return updated, nil
+}
+
+// UpdatePassword, for the given user ID, updates user's
+// password with the new password. Before updating, it
+// checks whether the current password are correct.
+func (s *service) UpdatePassword(ctx context.Context, userID int64, newPassword string, currentPassword string) error {
+	if newPassword == "" || currentPassword == "" {
+		return user.ErrInvalidPassword
+	}
+	err := validatePassword(newPassword)
 	if err != nil {
 		return err
 	}
 
-	// update fields
-	current.Password = hash
-	current.UpdateTime = s.timeNow()
+	// get pg store client using transaction
+	pgStoreClient, err := s.pgStore.NewClient(true)
+	if err != nil {
+		return err
+	}
 
-	// update user
-	err = pgStoreClient.UpdateUser(ctx, current)
+	err = s.updatePassword(ctx, pgStoreClient, userID, newPassword, currentPassword)
 	if err != nil {
+		pgStoreClient.Rollback()
 		return err
 	}
 
-	return nil
+	return pgStoreClient.Commit()
 }
 
 // ResetPassword, for the given user ID, updates user's
@@ -98,50 +161,142 @@ func (s *service) ResetPassword(ctx context.Context, userID int64, newPassword s
 	if newPassword == "" {
 		return user.ErrInvalidPassword
 	}
-
-	// hash password
-	hash, err := generateHash(newPassword, s.config.PasswordSalt)
+	err := validatePassword(newPassword)
 	if err != nil {
 		return err
 	}
 
-	// get pg store client without using transaction
-	pgStoreClient, err := s.pgStore.NewClient(false)
+	// get pg store client using transaction
+	pgStoreClient, err := s.pgStore.NewClient(true)
 	if err != nil {
 		return err
 	}
 
-	// get user current data
-	current, err := pgStoreClient.GetUserByID(ctx, userID)
+	err = s.updatePassword(ctx, pgStoreClient, userID, newPassword, "")
 	if err != nil {
+		pgStoreClient.Rollback()
 		return err
 	}
 
+	return pgStoreClient.Commit()
+}
+
+// updateProfile updates name and phone number of the user
+// with the user ID of the given user using the given pg
+// store client, which should use transaction.
+func (s *service) updateProfile(ctx context.Context, pgStoreClient postgresql.PGStoreClient, reqUser user.User) (user.User, error) {
+	// get user current data, locked so other fields are not
+	// overwritten with stale values
+	current, err := pgStoreClient.GetUserByIDForUpdate(ctx, reqUser.ID)
+	if err != nil {
+		return user.User{}, err
+	}
+
 	// update fields
-	current.Password = hash
+	if reqUser.Name != "" {
+		current.Name = reqUser.Name
+	}
+	if reqUser.PhoneNumber != "" {
+		current.PhoneNumber = reqUser.PhoneNumber
+	}
 	current.UpdateTime = s.timeNow()
 
+	// validate fields
+	err = validateUser(current)
+	if err != nil {
+		return user.User{}, err
+	}
+
 	// update user
 	err = pgStoreClient.UpdateUser(ctx, current)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	return current, nil
+}
+
+// updatePassword updates password of the user with the
+// given user ID with the new password using the given pg
+// store client, which should use transaction. The current
+// password is checked before updating unless it is empty.
+func (s *service) updatePassword(ctx context.Context, pgStoreClient postgresql.PGStoreClient, userID int64, newPassword string, currentPassword string) error {
+	// get user current data, locked so other fields are not
+	// overwritten with stale values
+	current, err := pgStoreClient.GetUserByIDForUpdate(ctx, userID)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// check current password
+	if currentPassword != "" {
+		_, err = s.checkPassword(ctx, current, currentPassword)
+		if err != nil {
+			return err
+		}
+	}
+
+	// hash password
+	hash, err := s.passwordHasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// update fields
+	current.Password = hash
+	current.UpdateTime = s.timeNow()
+
+	// update user
+	return pgStoreClient.UpdateUser(ctx, current)
 }
 
 // checkPassword checks the given password with the password
 // stored in store for the given user ID.
-func (s *service) checkPassword(ctx context.Context, data user.User, password string) error {
-	// compare paswords
-	match, err := compareHash(password, data.Password, s.config.PasswordSalt)
+//
+// It returns true if the password matches but its hash
+// should be upgraded to the preferred password hasher.
+func (s *service) checkPassword(ctx context.Context, data user.User, password string) (bool, error) {
+	// compare with the preferred hasher first, then with the
+	// ones the password may be hashed with before
+	match, err := s.passwordHasher.Verify(password, data.Password)
 	if err != nil {
-		return err
+		return false, err
+	}
+	if match {
+		return s.passwordHasher.NeedsRehash(data.Password), nil
+	}
+
+	for _, hasher := range s.fallbackPasswordHashers {
+		match, err = hasher.Verify(password, data.Password)
+		if err != nil {
+			return false, err
+		}
+		if match {
+			return true, nil
+		}
 	}
 
 	// wrong password
-	if !match {
-		return user.ErrInvalidPassword
+	return false, user.ErrInvalidPassword
+}
+
+// rehashPassword replaces the password hash of the given user
+// with the hash of the given password made by the preferred
+// password hasher, unless the password is changed in the
+// meantime.
+func (s *service) rehashPassword(ctx context.Context, pgStoreClient postgresql.PGStoreClient, data user.User, password string) error {
+	hash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	currentHash := data.Password
+	data.Password = hash
+	data.UpdateTime = s.timeNow()
+
+	err = pgStoreClient.RehashUserPassword(ctx, data, currentHash)
+	if err != nil && err != user.ErrDataNotFound {
+		return err
 	}
 
 	return nil
@@ -165,3 +320,27 @@ func validateUser(reqUser user.User) error {
 
 	return nil
 }
+
+// validatePassword validates the given password whether its
+// comply the password strength policy.
+func validatePassword(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < minPasswordLength || length > maxPasswordLength {
+		return user.ErrWeakPassword
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return user.ErrWeakPassword
+	}
+
+	return nil
+}
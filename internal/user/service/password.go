@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/synapsis-test/internal/user"
+	"github.com/synapsis-test/internal/user/mailer"
+	"github.com/synapsis-test/internal/user/store/postgresql"
+)
+
+// passwordResetTokenSize is the size in bytes of a password
+// reset token.
+const passwordResetTokenSize = 32
+
+func (s *service) RequestPasswordReset(ctx context.Context, email string) error {
+	// validate the given values
+	if email == "" {
+		return user.ErrInvalidEmail
+	}
+
+	// get pg store client without using transaction
+	pgStoreClient, err := s.pgStore.NewClient(false)
+	if err != nil {
+		return err
+	}
+
+	// get user current data, do not tell if there is none
+	current, err := pgStoreClient.GetUserByEmail(ctx, email)
+	if err == user.ErrDataNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := generateRandomToken(passwordResetTokenSize)
+	if err != nil {
+		return err
+	}
+
+	// only the hash is stored, so a leaked store can not be
+	// used to reset password
+	now := s.timeNow()
+	_, err = pgStoreClient.CreatePasswordResetToken(ctx, user.PasswordResetToken{
+		UserID:     current.ID,
+		TokenHash:  hashToken(token),
+		ExpireTime: now.Add(s.config.PasswordResetTokenExpiration),
+		CreateTime: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	// mail the token in background, so the response time
+	// does not tell whether the email exists
+	go s.sendMail(current.ID, mailer.Mail{
+		To:      current.Email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Use the following token to reset your password. It expires in %s.\n\n%s", s.config.PasswordResetTokenExpiration, token),
+	})
+
+	return nil
+}
+
+func (s *service) ResetPasswordWithToken(ctx context.Context, token string, newPassword string) error {
+	// validate the given values before the token is used
+	if token == "" {
+		return user.ErrInvalidToken
+	}
+	if newPassword == "" {
+		return user.ErrInvalidPassword
+	}
+	err := validatePassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// get pg store client using transaction, so the token is
+	// only used if the password is reset
+	pgStoreClient, err := s.pgStore.NewClient(true)
+	if err != nil {
+		return err
+	}
+
+	err = s.resetPasswordWithToken(ctx, pgStoreClient, token, newPassword)
+	if err != nil {
+		pgStoreClient.Rollback()
+		return err
+	}
+
+	return pgStoreClient.Commit()
+}
+
+// resetPasswordWithToken uses the given password reset token
+// to reset the password of the user who requested it, and
+// signs the user out of every session using the given pg
+// store client, which should use transaction.
+func (s *service) resetPasswordWithToken(ctx context.Context, pgStoreClient postgresql.PGStoreClient, token string, newPassword string) error {
+	now := s.timeNow()
+
+	// use the token, it can not be used again from now
+	userID, err := pgStoreClient.UsePasswordResetToken(ctx, hashToken(token), now)
+	if err != nil {
+		return err
+	}
+
+	err = s.updatePassword(ctx, pgStoreClient, userID, newPassword, "")
+	if err != nil {
+		return err
+	}
+
+	// the password may be reset because it is leaked, so the
+	// sessions signed in with it can not be refreshed anymore
+	return pgStoreClient.RevokeUserRefreshTokens(ctx, userID, now)
+}
+
+// sendMail sends the given mail to the user with the given
+// user ID on its own context, and only logs the failure.
+func (s *service) sendMail(userID int64, mail mailer.Mail) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+
+	err := s.mailer.Send(ctx, mail)
+	if err != nil {
+		log.Printf("[User Service][sendMail] Failed to send mail. userID: %d, subject: %s. Err: %s\n", userID, mail.Subject, err.Error())
+	}
+}
@@ -4,24 +4,76 @@ import (
 	"errors"
 	"time"
 
+	"github.com/go-redis/redis/v8"
+	"github.com/synapsis-test/internal/user/mailer"
 	"github.com/synapsis-test/internal/user/store/postgresql"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Following constans are config default values.
 const (
-	defaultTokenExpiration = 10000 * time.Hour
+	defaultTokenExpiration              = 15 * time.Minute
+	defaultRefreshTokenExpiration       = 30 * 24 * time.Hour
+	defaultPasswordResetTokenExpiration = 30 * time.Minute
+	defaultEmailVerificationExpiration  = 24 * time.Hour
+	defaultMaxLoginFailures             = 5
+	defaultMaxLoginFailuresPerIP        = 50
+	defaultLoginFailureWindow           = time.Hour
+	defaultLoginLockoutDuration         = 15 * time.Minute
+	defaultPasswordHashAlgorithm        = PasswordHashArgon2id
+	defaultBcryptCost                   = 12
+	defaultArgon2idMemory               = 19 * 1024 // KiB
+	defaultArgon2idIterations           = 2
+	defaultArgon2idParallelism          = 1
+)
+
+// Followings are the length limits of the password strength
+// policy, besides a password should contain at least a letter
+// and a digit.
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 128
+)
+
+// mailTimeout is the timeout to send a mail, which is sent
+// in background.
+const mailTimeout = 10 * time.Second
+
+// emailVerificationResendInterval is the minimum interval to
+// resend email verification to the same email.
+const emailVerificationResendInterval = time.Minute
+
+// Followings are the delay before a login can be attempted
+// again for the same email after a failure, which doubles
+// on every consecutive failure.
+const (
+	loginBackoffBase = time.Second
+	loginBackoffMax  = time.Minute
 )
 
 // Followings are the known error returned from service.
 var (
 	errMissingMandatoryConfig = errors.New("missing mandatory config")
+	errInvalidConfig          = errors.New("invalid config")
 )
 
 // service implements user.Service.
 type service struct {
-	pgStore postgresql.PGStore
-	config  Config
-	timeNow func() time.Time
+	pgStore     postgresql.PGStore
+	redisClient *redis.Client
+	mailer      mailer.Mailer
+	config      Config
+	timeNow     func() time.Time
+
+	// passwordHasher hashes new passwords, while
+	// fallbackPasswordHashers only verify passwords hashed
+	// with other algorithms before.
+	passwordHasher          PasswordHasher
+	fallbackPasswordHashers []PasswordHasher
+
+	// dummyPasswordHash is compared with the password of an
+	// unknown email, so login takes as long as for a known one.
+	dummyPasswordHash string
 }
 
 // Config denotes service configuration
@@ -29,25 +81,68 @@ type service struct {
 // Adding a new field should also add the corresponding default
 // value in getDefaultConfig().
 type Config struct {
-	PasswordSalt    string
-	TokenExpiration time.Duration
-	TokenSecretKey  string
+	PasswordSalt                 string
+	TokenExpiration              time.Duration // access token
+	RefreshTokenExpiration       time.Duration
+	TokenSecretKey               string
+	PasswordResetTokenExpiration time.Duration
+	EmailVerificationExpiration  time.Duration
+
+	// RequireVerifiedEmail only allows users with verified
+	// email to login.
+	RequireVerifiedEmail bool
+
+	// MaxLoginFailures is the number of failed logins for an
+	// email before the account is locked for
+	// LoginLockoutDuration, and MaxLoginFailuresPerIP is the
+	// number of failed logins from an IP address before it is
+	// refused. Failures are forgotten after LoginFailureWindow
+	// without any failure.
+	MaxLoginFailures      int
+	MaxLoginFailuresPerIP int
+	LoginFailureWindow    time.Duration
+	LoginLockoutDuration  time.Duration
+
+	// PasswordHashAlgorithm is the algorithm to hash new
+	// passwords, either PasswordHashArgon2id or
+	// PasswordHashBcrypt, configured by the following
+	// parameters. Passwords hashed with other algorithm or
+	// parameters are rehashed on login.
+	PasswordHashAlgorithm string
+	BcryptCost            int
+	Argon2idMemory        uint32 // in KiB
+	Argon2idIterations    uint32
+	Argon2idParallelism   uint8
 }
 
 // getDefaultConfig returns service configuration with the
 // predefined default values.
 func getDefaultConfig() Config {
 	return Config{
-		TokenExpiration: defaultTokenExpiration,
+		TokenExpiration:              defaultTokenExpiration,
+		RefreshTokenExpiration:       defaultRefreshTokenExpiration,
+		PasswordResetTokenExpiration: defaultPasswordResetTokenExpiration,
+		EmailVerificationExpiration:  defaultEmailVerificationExpiration,
+		MaxLoginFailures:             defaultMaxLoginFailures,
+		MaxLoginFailuresPerIP:        defaultMaxLoginFailuresPerIP,
+		LoginFailureWindow:           defaultLoginFailureWindow,
+		LoginLockoutDuration:         defaultLoginLockoutDuration,
+		PasswordHashAlgorithm:        defaultPasswordHashAlgorithm,
+		BcryptCost:                   defaultBcryptCost,
+		Argon2idMemory:               defaultArgon2idMemory,
+		Argon2idIterations:           defaultArgon2idIterations,
+		Argon2idParallelism:          defaultArgon2idParallelism,
 	}
 }
 
 // New creates a new service.
-func New(pgStore postgresql.PGStore, options ...Option) (*service, error) {
+func New(pgStore postgresql.PGStore, redisClient *redis.Client, mailer mailer.Mailer, options ...Option) (*service, error) {
 	s := &service{
-		pgStore: pgStore,
-		config:  getDefaultConfig(),
-		timeNow: time.Now,
+		pgStore:     pgStore,
+		redisClient: redisClient,
+		mailer:      mailer,
+		config:      getDefaultConfig(),
+		timeNow:     time.Now,
 	}
 
 	// apply options
@@ -61,6 +156,30 @@ func New(pgStore postgresql.PGStore, options ...Option) (*service, error) {
 	if s.config.PasswordSalt == "" || s.config.TokenSecretKey == "" {
 		return nil, errMissingMandatoryConfig
 	}
+	if s.config.BcryptCost < bcrypt.MinCost || s.config.BcryptCost > bcrypt.MaxCost {
+		return nil, errInvalidConfig
+	}
+
+	// use the configured password hasher unless stated
+	// otherwise
+	if s.passwordHasher == nil {
+		hasher, err := newPasswordHasher(s.config.PasswordHashAlgorithm, s.config)
+		if err != nil {
+			return nil, err
+		}
+		s.passwordHasher = hasher
+	}
+	s.fallbackPasswordHashers = newFallbackPasswordHashers(s.config.PasswordHashAlgorithm, s.config)
+
+	// hash a random password, so no password ever matches it
+	dummyPassword, err := generateRandomToken(passwordResetTokenSize)
+	if err != nil {
+		return nil, err
+	}
+	s.dummyPasswordHash, err = s.passwordHasher.Hash(dummyPassword)
+	if err != nil {
+		return nil, err
+	}
 
 	return s, nil
 }
@@ -77,9 +196,58 @@ func WithConfig(config Config) Option {
 		if config.TokenExpiration > 0 {
 			s.config.TokenExpiration = config.TokenExpiration
 		}
+		if config.RefreshTokenExpiration > 0 {
+			s.config.RefreshTokenExpiration = config.RefreshTokenExpiration
+		}
 		if config.TokenSecretKey != "" {
 			s.config.TokenSecretKey = config.TokenSecretKey
 		}
+		if config.PasswordResetTokenExpiration > 0 {
+			s.config.PasswordResetTokenExpiration = config.PasswordResetTokenExpiration
+		}
+		if config.EmailVerificationExpiration > 0 {
+			s.config.EmailVerificationExpiration = config.EmailVerificationExpiration
+		}
+		if config.RequireVerifiedEmail {
+			s.config.RequireVerifiedEmail = config.RequireVerifiedEmail
+		}
+		if config.MaxLoginFailures > 0 {
+			s.config.MaxLoginFailures = config.MaxLoginFailures
+		}
+		if config.MaxLoginFailuresPerIP > 0 {
+			s.config.MaxLoginFailuresPerIP = config.MaxLoginFailuresPerIP
+		}
+		if config.LoginFailureWindow > 0 {
+			s.config.LoginFailureWindow = config.LoginFailureWindow
+		}
+		if config.LoginLockoutDuration > 0 {
+			s.config.LoginLockoutDuration = config.LoginLockoutDuration
+		}
+		if config.PasswordHashAlgorithm != "" {
+			s.config.PasswordHashAlgorithm = config.PasswordHashAlgorithm
+		}
+		if config.BcryptCost > 0 {
+			s.config.BcryptCost = config.BcryptCost
+		}
+		if config.Argon2idMemory > 0 {
+			s.config.Argon2idMemory = config.Argon2idMemory
+		}
+		if config.Argon2idIterations > 0 {
+			s.config.Argon2idIterations = config.Argon2idIterations
+		}
+		if config.Argon2idParallelism > 0 {
+			s.config.Argon2idParallelism = config.Argon2idParallelism
+		}
+		return nil
+	}
+}
+
+// WithPasswordHasher returns Option to hash new passwords
+// with the given password hasher instead of the configured
+// one.
+func WithPasswordHasher(hasher PasswordHasher) Option {
+	return func(s *service) error {
+		s.passwordHasher = hasher
 		return nil
 	}
 }
@@ -2,78 +2,329 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/synapsis-test/internal/user"
+	"github.com/synapsis-test/internal/user/store/postgresql"
+)
+
+// Followings are the size in bytes of the random values
+// generated for tokens.
+const (
+	tokenIDSize      = 16
+	tokenFamilySize  = 16
+	refreshTokenSize = 32
 )
 
 // jwtClaimss is the claims encapsulated in JWT-generated token.
 type jwtClaims struct {
-	UserID int64  `json:"user_id"`
-	Email  string `json:"email"`
+	UserID        int64  `json:"user_id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Role          string `json:"role"`
 	jwt.RegisteredClaims
 }
 
 // parseTokenData parse token data from jwt claims.
 func (jwtc jwtClaims) parseTokenData() user.TokenData {
 	return user.TokenData{
-		UserID: jwtc.UserID,
-		Email:  jwtc.Email,
+		UserID:        jwtc.UserID,
+		Email:         jwtc.Email,
+		EmailVerified: jwtc.EmailVerified,
+		Role:          user.ParseRole(jwtc.Role),
 	}
 }
 
 // formatTokenData format token data into jwt claims.
 func formatTokenData(data user.TokenData) jwtClaims {
 	return jwtClaims{
-		UserID: data.UserID,
-		Email:  data.Email,
+		UserID:        data.UserID,
+		Email:         data.Email,
+		EmailVerified: data.EmailVerified,
+		Role:          data.Role.String(),
 	}
 }
 
-func (s *service) LoginBasic(ctx context.Context, email string, password string) (string, user.TokenData, error) {
+func (s *service) LoginBasic(ctx context.Context, email string, password string, ip string) (user.Token, user.TokenData, error) {
 	// validate the given values
 	if email == "" {
-		return "", user.TokenData{}, user.ErrInvalidEmail
+		return user.Token{}, user.TokenData{}, user.ErrInvalidEmail
 	}
 	if password == "" {
-		return "", user.TokenData{}, user.ErrInvalidPassword
+		return user.Token{}, user.TokenData{}, user.ErrInvalidPassword
+	}
+
+	// refuse login attempted too soon or for locked account,
+	// otherwise it is counted as failed until it succeeds
+	err := s.claimLoginAttempt(ctx, email, ip)
+	if err != nil {
+		return user.Token{}, user.TokenData{}, err
 	}
 
 	// get pg store client without using transaction
 	pgStoreClient, err := s.pgStore.NewClient(false)
 	if err != nil {
-		return "", user.TokenData{}, err
+		return user.Token{}, user.TokenData{}, err
 	}
 
-	// get user current data
+	// get user current data and check password, unknown email
+	// is counted as failure too so it can not be told apart
+	var rehash bool
 	current, err := pgStoreClient.GetUserByEmail(ctx, email)
+	if err == nil {
+		rehash, err = s.checkPassword(ctx, current, password)
+	}
+	if err == user.ErrDataNotFound {
+		// compare anyway, so the response time does not tell
+		// whether the email exists
+		s.passwordHasher.Verify(password, s.dummyPasswordHash)
+	}
 	if err != nil {
-		return "", user.TokenData{}, err
+		return user.Token{}, user.TokenData{}, err
+	}
+
+	// the login succeeds, forget the failures
+	err = s.resetLoginFailures(ctx, email, ip)
+	if err != nil {
+		log.Printf("[User Service][LoginBasic] Failed to reset login failures. userID: %d. Err: %s\n", current.ID, err.Error())
+	}
+
+	// upgrade the password hash made with outdated algorithm
+	// or parameters, the login still succeeds if it fails
+	if rehash {
+		err = s.rehashPassword(ctx, pgStoreClient, current, password)
+		if err != nil {
+			log.Printf("[User Service][LoginBasic] Failed to rehash password. userID: %d. Err: %s\n", current.ID, err.Error())
+		}
+	}
+
+	// check email verification if required
+	if s.config.RequireVerifiedEmail && !current.EmailVerified {
+		return user.Token{}, user.TokenData{}, user.ErrEmailNotVerified
 	}
 
-	// check password
-	err = s.checkPassword(ctx, current, password)
+	// every login starts a new refresh token family
+	familyID, err := generateRandomToken(tokenFamilySize)
 	if err != nil {
-		return "", user.TokenData{}, err
+		return user.Token{}, user.TokenData{}, err
 	}
 
-	// generate token
+	// issue token
 	tokenData := user.TokenData{
-		UserID: current.ID,
-		Email:  current.Email,
+		UserID:        current.ID,
+		Email:         current.Email,
+		EmailVerified: current.EmailVerified,
+		Role:          current.Role,
 	}
-	token, err := s.generateToken(ctx, tokenData)
+	token, err := s.issueToken(ctx, pgStoreClient, tokenData, familyID)
 	if err != nil {
-		return "", user.TokenData{}, err
+		return user.Token{}, user.TokenData{}, err
 	}
 
 	return token, tokenData, nil
 }
 
-// TODO: check for expired token error from internal JWT library
 func (s *service) ValidateToken(ctx context.Context, token string) (user.TokenData, error) {
+	claims, err := s.parseToken(token)
+	if err != nil {
+		return user.TokenData{}, err
+	}
+
+	// check whether token is revoked or not
+	revoked, err := s.redisClient.Exists(ctx, formatTokenDenylistRedisKey(claims.ID)).Result()
+	if err != nil {
+		return user.TokenData{}, err
+	}
+	if revoked > 0 {
+		return user.TokenData{}, user.ErrRevokedToken
+	}
+
+	return claims.parseTokenData(), nil
+}
+
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (user.Token, error) {
+	if refreshToken == "" {
+		return user.Token{}, user.ErrInvalidToken
+	}
+
+	// get pg store client using transaction
+	pgStoreClient, err := s.pgStore.NewClient(true)
+	if err != nil {
+		return user.Token{}, err
+	}
+
+	token, err := s.rotateRefreshToken(ctx, pgStoreClient, refreshToken)
+	if err != nil && err != user.ErrRevokedToken {
+		pgStoreClient.Rollback()
+		return user.Token{}, err
+	}
+
+	// the revoked family should be kept on reuse
+	if commitErr := pgStoreClient.Commit(); commitErr != nil {
+		return user.Token{}, commitErr
+	}
+
+	return token, err
+}
+
+func (s *service) Logout(ctx context.Context, accessToken string, refreshToken string) error {
+	claims, err := s.parseToken(accessToken)
+	if err != nil {
+		return err
+	}
+
+	now := s.timeNow()
+
+	// revoke the whole refresh token family
+	if refreshToken != "" {
+		// get pg store client without using transaction
+		pgStoreClient, err := s.pgStore.NewClient(false)
+		if err != nil {
+			return err
+		}
+
+		current, err := pgStoreClient.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+		if err == user.ErrDataNotFound {
+			return user.ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+
+		// refresh token of another user
+		if current.UserID != claims.UserID {
+			return user.ErrInvalidToken
+		}
+
+		err = pgStoreClient.RevokeRefreshTokenFamily(ctx, current.FamilyID, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	// deny the access token until it expires
+	ttl := claims.ExpiresAt.Time.Sub(now)
+	if ttl <= 0 {
+		return nil
+	}
+
+	return s.redisClient.Set(ctx, formatTokenDenylistRedisKey(claims.ID), 1, ttl).Err()
+}
+
+// rotateRefreshToken revokes the given refresh token and
+// issues a new pair of token in the same family using the
+// given pg store client, which should use transaction.
+//
+// The family is revoked if the refresh token is already
+// revoked, and user.ErrRevokedToken is returned.
+func (s *service) rotateRefreshToken(ctx context.Context, pgStoreClient postgresql.PGStoreClient, refreshToken string) (user.Token, error) {
+	current, err := pgStoreClient.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err == user.ErrDataNotFound {
+		return user.Token{}, user.ErrInvalidToken
+	}
+	if err != nil {
+		return user.Token{}, err
+	}
+
+	now := s.timeNow()
+
+	// an exchanged token is used again, it might be stolen
+	if !current.RevokeTime.IsZero() {
+		return user.Token{}, s.revokeReusedRefreshToken(ctx, pgStoreClient, current)
+	}
+
+	if !now.Before(current.ExpireTime) {
+		return user.Token{}, user.ErrExpiredToken
+	}
+
+	// revoke the token, it might be exchanged in between
+	current.RevokeTime = now
+	err = pgStoreClient.RevokeRefreshToken(ctx, current)
+	if err == user.ErrRevokedToken {
+		return user.Token{}, s.revokeReusedRefreshToken(ctx, pgStoreClient, current)
+	}
+	if err != nil {
+		return user.Token{}, err
+	}
+
+	// get user current data, so the new token is up to date
+	owner, err := pgStoreClient.GetUserByID(ctx, current.UserID)
+	if err != nil {
+		return user.Token{}, err
+	}
+
+	tokenData := user.TokenData{
+		UserID:        owner.ID,
+		Email:         owner.Email,
+		EmailVerified: owner.EmailVerified,
+		Role:          owner.Role,
+	}
+
+	return s.issueToken(ctx, pgStoreClient, tokenData, current.FamilyID)
+}
+
+// revokeReusedRefreshToken revokes the family of the given
+// reused refresh token. It returns user.ErrRevokedToken if
+// the family is revoked.
+func (s *service) revokeReusedRefreshToken(ctx context.Context, pgStoreClient postgresql.PGStoreClient, reused user.RefreshToken) error {
+	log.Printf("[User Service][RefreshToken] Refresh token is reused, revoking its family. userID: %d, tokenID: %d\n", reused.UserID, reused.ID)
+
+	err := pgStoreClient.RevokeRefreshTokenFamily(ctx, reused.FamilyID, s.timeNow())
+	if err != nil {
+		return err
+	}
+
+	return user.ErrRevokedToken
+}
+
+// issueToken returns a new pair of access token that
+// encapsulates the given token data, and refresh token in
+// the given family. The refresh token is stored using the
+// given pg store client.
+func (s *service) issueToken(ctx context.Context, pgStoreClient postgresql.PGStoreClient, data user.TokenData, familyID string) (user.Token, error) {
+	accessToken, claims, err := s.generateToken(ctx, data)
+	if err != nil {
+		return user.Token{}, err
+	}
+
+	refreshToken, err := generateRandomToken(refreshTokenSize)
+	if err != nil {
+		return user.Token{}, err
+	}
+
+	// only the hash is stored, so a leaked store can not be
+	// used to refresh
+	now := s.timeNow()
+	_, err = pgStoreClient.CreateRefreshToken(ctx, user.RefreshToken{
+		UserID:     data.UserID,
+		FamilyID:   familyID,
+		TokenHash:  hashToken(refreshToken),
+		ExpireTime: now.Add(s.config.RefreshTokenExpiration),
+		CreateTime: now,
+	})
+	if err != nil {
+		return user.Token{}, err
+	}
+
+	return user.Token{
+		AccessToken:           accessToken,
+		AccessTokenExpireTime: claims.ExpiresAt.Time,
+		RefreshToken:          refreshToken,
+	}, nil
+}
+
+// parseToken validates the given access token and returns
+// the claims encapsulated in it. It does not check whether
+// the token is revoked.
+func (s *service) parseToken(token string) (*jwtClaims, error) {
 	if token == "" {
-		return user.TokenData{}, user.ErrInvalidToken
+		return nil, user.ErrInvalidToken
 	}
 
 	// get jwt token object
@@ -81,45 +332,45 @@ func (s *service) ValidateToken(ctx context.Context, token string) (user.TokenDa
 		return []byte(s.config.TokenSecretKey), nil
 	})
 	if err != nil {
-		if err == jwt.ErrSignatureInvalid {
-			return user.TokenData{}, user.ErrInvalidToken
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, user.ErrExpiredToken
 		}
-		return user.TokenData{}, err
+		if errors.Is(err, jwt.ErrSignatureInvalid) {
+			return nil, user.ErrInvalidToken
+		}
+		return nil, err
 	}
 
 	// check whether token is valid or not (from expirations time)
 	if !jwtToken.Valid {
-		return user.TokenData{}, user.ErrExpiredToken
+		return nil, user.ErrExpiredToken
 	}
 
-	// parse jwt claims
+	// parse jwt claims, token without ID can not be revoked
 	claims, ok := jwtToken.Claims.(*jwtClaims)
-	if !ok {
-		return user.TokenData{}, user.ErrInvalidToken
+	if !ok || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil, user.ErrInvalidToken
 	}
 
-	return claims.parseTokenData(), nil
-}
-
-func (s *service) RefreshToken(ctx context.Context, token string) (string, error) {
-	// validate token
-	data, err := s.ValidateToken(ctx, token)
-	if err != nil {
-		return "", err
-	}
-
-	// generate a new token
-	return s.generateToken(ctx, data)
+	return claims, nil
 }
 
 // generateToken returns a new token that encapsulates the
 // given token data with some additional information:
+//   - token ID
 //   - token expiration time
 //
 // Token is generated using JWT HS256.
-func (s *service) generateToken(ctx context.Context, data user.TokenData) (string, error) {
+func (s *service) generateToken(ctx context.Context, data user.TokenData) (string, jwtClaims, error) {
 	claims := formatTokenData(data)
 
+	// add token ID to be able to revoke the token
+	tokenID, err := generateRandomToken(tokenIDSize)
+	if err != nil {
+		return "", jwtClaims{}, err
+	}
+	claims.ID = tokenID
+
 	// add expirations time
 	expiresAt := s.timeNow().Add(s.config.TokenExpiration)
 	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
@@ -129,9 +380,33 @@ func (s *service) generateToken(ctx context.Context, data user.TokenData) (strin
 
 	// sign token with secret key
 	signedToken, err := token.SignedString([]byte(s.config.TokenSecretKey))
+	if err != nil {
+		return "", jwtClaims{}, err
+	}
+
+	return signedToken, claims, nil
+}
+
+// generateRandomToken returns hex encoded random bytes of
+// the given size.
+func generateRandomToken(size int) (string, error) {
+	b := make([]byte, size)
+	_, err := rand.Read(b)
 	if err != nil {
 		return "", err
 	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken returns the hex encoded SHA-256 hash of the
+// given token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
 
-	return signedToken, nil
+// formatTokenDenylistRedisKey returns the redis key to deny
+// the token with the given token ID.
+func formatTokenDenylistRedisKey(tokenID string) string {
+	return fmt.Sprintf("user:token:denylist:%s", tokenID)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -16,11 +17,13 @@ import (
 func (sc *storeClient) CreateUser(ctx context.Context, reqUser user.User) (int64, error) {
 	// construct arguments filled with fields for the query
 	argsKV := map[string]interface{}{
-		"email":        reqUser.Email,
-		"name":         reqUser.Name,
-		"password":     reqUser.Password,
-		"phone_number": reqUser.PhoneNumber,
-		"create_time":  reqUser.CreateTime,
+		"email":          reqUser.Email,
+		"email_verified": reqUser.EmailVerified,
+		"name":           reqUser.Name,
+		"password":       reqUser.Password,
+		"phone_number":   reqUser.PhoneNumber,
+		"role":           reqUser.Role,
+		"create_time":    reqUser.CreateTime,
 	}
 
 	// prepare query
@@ -65,6 +68,24 @@ func (sc *storeClient) GetUserByID(ctx context.Context, userID int64) (user.User
 	return udb.format(), nil
 }
 
+// GetUserByIDForUpdate selects user with the given user ID
+// and locks it until the transaction ends, so it is not
+// updated in between. The client should use transaction.
+func (sc *storeClient) GetUserByIDForUpdate(ctx context.Context, userID int64) (user.User, error) {
+	query := fmt.Sprintf(queryGetUser, "u.id = $1 FOR UPDATE")
+	// query single row
+	var udb userDB
+	err := sc.q.QueryRowx(query, userID).StructScan(&udb)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user.User{}, user.ErrDataNotFound
+		}
+		return user.User{}, err
+	}
+
+	return udb.format(), nil
+}
+
 // GetUserByEmail select a user with the given
 // email.
 func (sc *storeClient) GetUserByEmail(ctx context.Context, email string) (user.User, error) {
@@ -85,16 +106,22 @@ func (sc *storeClient) GetUserByEmail(ctx context.Context, email string) (user.U
 // UpdateUser updates existing data with the given data
 // for a user specified with the given user ID.
 //
-// UpdateUser do updates on all the fields except ID, Email,
-// PICName, CompanyName, PhoneNumber, and CreateTime. So, make
-// sure to use current values in the given data if do
-// not want to update some specific fields.
+// UpdateUser do updates on all the fields except ID, Role,
+// and CreateTime. So, make sure to use current values in the
+// given data if do not want to update some specific fields.
+//
+// It returns user.ErrUserAlreadyExist if the email is
+// already used by other user.
 func (sc *storeClient) UpdateUser(ctx context.Context, reqUser user.User) error {
 	// construct arguments filled with fields for the query
 	argsKV := map[string]interface{}{
-		"id":          reqUser.ID,
-		"password":    reqUser.Password,
-		"update_time": reqUser.UpdateTime,
+		"id":             reqUser.ID,
+		"email":          reqUser.Email,
+		"email_verified": reqUser.EmailVerified,
+		"name":           reqUser.Name,
+		"password":       reqUser.Password,
+		"phone_number":   reqUser.PhoneNumber,
+		"update_time":    reqUser.UpdateTime,
 	}
 
 	// prepare query
@@ -121,3 +148,404 @@ func (sc *storeClient) UpdateUser(ctx context.Context, reqUser user.User) error
 
 	return err
 }
+
+// RehashUserPassword replaces the password hash of the
+// given user with the given one only if the stored hash is
+// still the given current hash.
+//
+// It returns user.ErrDataNotFound if the password is
+// changed in the meantime.
+func (sc *storeClient) RehashUserPassword(ctx context.Context, reqUser user.User, currentHash string) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"id":               reqUser.ID,
+		"password":         reqUser.Password,
+		"update_time":      reqUser.UpdateTime,
+		"current_password": currentHash,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryRehashUserPassword, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	result, err := sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	// the password is changed in the meantime
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return user.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// CreateRefreshToken inserts the given refresh token.
+//
+// CreateRefreshToken returns created refresh token ID.
+func (sc *storeClient) CreateRefreshToken(ctx context.Context, token user.RefreshToken) (int64, error) {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"user_id":     token.UserID,
+		"family_id":   token.FamilyID,
+		"token_hash":  token.TokenHash,
+		"expire_time": token.ExpireTime,
+		"create_time": token.CreateTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryCreateRefreshToken, argsKV)
+	if err != nil {
+		return 0, err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	var tokenID int64
+	err = sc.q.QueryRowx(query, args...).Scan(&tokenID)
+	if err != nil {
+		return 0, err
+	}
+
+	return tokenID, nil
+}
+
+// GetRefreshTokenByHash selects a refresh token with the
+// given token hash.
+func (sc *storeClient) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (user.RefreshToken, error) {
+	query := fmt.Sprintf(queryGetRefreshToken, "rt.token_hash = $1")
+	// query single row
+	var rtdb refreshTokenDB
+	err := sc.q.QueryRowx(query, tokenHash).StructScan(&rtdb)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user.RefreshToken{}, user.ErrDataNotFound
+		}
+		return user.RefreshToken{}, err
+	}
+
+	return rtdb.format(), nil
+}
+
+// RevokeRefreshToken revokes the given refresh token at
+// its revoke time.
+//
+// It returns user.ErrRevokedToken if the refresh token is
+// already revoked.
+func (sc *storeClient) RevokeRefreshToken(ctx context.Context, token user.RefreshToken) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"id":          token.ID,
+		"revoke_time": token.RevokeTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryRevokeRefreshToken, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	result, err := sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	// the token is revoked in between
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return user.ErrRevokedToken
+	}
+
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every refresh token
+// that is not revoked yet in the given family at the given
+// revoke time.
+func (sc *storeClient) RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokeTime time.Time) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"family_id":   familyID,
+		"revoke_time": revokeTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryRevokeRefreshTokenFamily, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	_, err = sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes every refresh token that
+// is not revoked yet of the given user at the given revoke
+// time.
+func (sc *storeClient) RevokeUserRefreshTokens(ctx context.Context, userID int64, revokeTime time.Time) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"user_id":     userID,
+		"revoke_time": revokeTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryRevokeUserRefreshTokens, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	_, err = sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreatePasswordResetToken inserts the given password
+// reset token.
+//
+// CreatePasswordResetToken returns created password reset
+// token ID.
+func (sc *storeClient) CreatePasswordResetToken(ctx context.Context, token user.PasswordResetToken) (int64, error) {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"user_id":     token.UserID,
+		"token_hash":  token.TokenHash,
+		"expire_time": token.ExpireTime,
+		"create_time": token.CreateTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryCreatePasswordResetToken, argsKV)
+	if err != nil {
+		return 0, err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	var tokenID int64
+	err = sc.q.QueryRowx(query, args...).Scan(&tokenID)
+	if err != nil {
+		return 0, err
+	}
+
+	return tokenID, nil
+}
+
+// UsePasswordResetToken marks the password reset token
+// with the given token hash as used at the given use time,
+// and returns the user ID who requested it.
+//
+// It returns user.ErrInvalidToken if there is no such
+// token that is neither used nor expired.
+func (sc *storeClient) UsePasswordResetToken(ctx context.Context, tokenHash string, useTime time.Time) (int64, error) {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"token_hash": tokenHash,
+		"use_time":   useTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryUsePasswordResetToken, argsKV)
+	if err != nil {
+		return 0, err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	var userID int64
+	err = sc.q.QueryRowx(query, args...).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, user.ErrInvalidToken
+		}
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// UpdateUserEmailVerified updates whether the email of the
+// given user is verified.
+func (sc *storeClient) UpdateUserEmailVerified(ctx context.Context, reqUser user.User) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"id":             reqUser.ID,
+		"email_verified": reqUser.EmailVerified,
+		"update_time":    reqUser.UpdateTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryUpdateUserEmailVerified, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	_, err = sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateEmailVerificationToken inserts the given email
+// verification token.
+//
+// CreateEmailVerificationToken returns created email
+// verification token ID.
+func (sc *storeClient) CreateEmailVerificationToken(ctx context.Context, token user.EmailVerificationToken) (int64, error) {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"user_id":     token.UserID,
+		"email":       token.Email,
+		"token_hash":  token.TokenHash,
+		"expire_time": token.ExpireTime,
+		"create_time": token.CreateTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryCreateEmailVerificationToken, argsKV)
+	if err != nil {
+		return 0, err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	var tokenID int64
+	err = sc.q.QueryRowx(query, args...).Scan(&tokenID)
+	if err != nil {
+		return 0, err
+	}
+
+	return tokenID, nil
+}
+
+// UseEmailVerificationToken marks the email verification
+// token with the given token hash as used at the given use
+// time, and returns the used token.
+//
+// It returns user.ErrInvalidToken if there is no such
+// token that is neither used nor expired.
+func (sc *storeClient) UseEmailVerificationToken(ctx context.Context, tokenHash string, useTime time.Time) (user.EmailVerificationToken, error) {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"token_hash": tokenHash,
+		"use_time":   useTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryUseEmailVerificationToken, argsKV)
+	if err != nil {
+		return user.EmailVerificationToken{}, err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return user.EmailVerificationToken{}, err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	var evtdb emailVerificationTokenDB
+	err = sc.q.QueryRowx(query, args...).StructScan(&evtdb)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user.EmailVerificationToken{}, user.ErrInvalidToken
+		}
+		return user.EmailVerificationToken{}, err
+	}
+
+	return evtdb.format(), nil
+}
+
+// RevokeEmailVerificationTokens marks every email
+// verification token of the given user ID that is not used
+// yet as used at the given use time.
+func (sc *storeClient) RevokeEmailVerificationTokens(ctx context.Context, userID int64, useTime time.Time) error {
+	// construct arguments filled with fields for the query
+	argsKV := map[string]interface{}{
+		"user_id":  userID,
+		"use_time": useTime,
+	}
+
+	// prepare query
+	query, args, err := sqlx.Named(queryRevokeEmailVerificationTokens, argsKV)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	query = sc.q.Rebind(query)
+
+	// execute query
+	_, err = sc.q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -66,24 +66,28 @@ func (sc *storeClient) Rollback() error {
 
 // userDB denotes a data in the store.
 type userDB struct {
-	ID          int64      `db:"id"`
-	Email       string     `db:"email"`
-	Name        string     `db:"name"`
-	Password    string     `db:"password"`
-	PhoneNumber string     `db:"phone_number"`
-	CreateTime  time.Time  `db:"create_time"`
-	UpdateTime  *time.Time `db:"update_time"`
+	ID            int64      `db:"id"`
+	Email         string     `db:"email"`
+	EmailVerified bool       `db:"email_verified"`
+	Name          string     `db:"name"`
+	Password      string     `db:"password"`
+	PhoneNumber   string     `db:"phone_number"`
+	Role          user.Role  `db:"role"`
+	CreateTime    time.Time  `db:"create_time"`
+	UpdateTime    *time.Time `db:"update_time"`
 }
 
 // format formats database struct into domain struct.
 func (udb *userDB) format() user.User {
 	u := user.User{
-		ID:          udb.ID,
-		Email:       udb.Email,
-		Name:        udb.Name,
-		Password:    udb.Password,
-		PhoneNumber: udb.PhoneNumber,
-		CreateTime:  udb.CreateTime,
+		ID:            udb.ID,
+		Email:         udb.Email,
+		EmailVerified: udb.EmailVerified,
+		Name:          udb.Name,
+		Password:      udb.Password,
+		PhoneNumber:   udb.PhoneNumber,
+		Role:          udb.Role,
+		CreateTime:    udb.CreateTime,
 	}
 
 	if udb.UpdateTime != nil {
@@ -92,3 +96,62 @@ func (udb *userDB) format() user.User {
 
 	return u
 }
+
+// refreshTokenDB denotes a refresh token data in the store.
+type refreshTokenDB struct {
+	ID         int64      `db:"id"`
+	UserID     int64      `db:"user_id"`
+	FamilyID   string     `db:"family_id"`
+	TokenHash  string     `db:"token_hash"`
+	ExpireTime time.Time  `db:"expire_time"`
+	RevokeTime *time.Time `db:"revoke_time"`
+	CreateTime time.Time  `db:"create_time"`
+}
+
+// format formats database struct into domain struct.
+func (rtdb *refreshTokenDB) format() user.RefreshToken {
+	rt := user.RefreshToken{
+		ID:         rtdb.ID,
+		UserID:     rtdb.UserID,
+		FamilyID:   rtdb.FamilyID,
+		TokenHash:  rtdb.TokenHash,
+		ExpireTime: rtdb.ExpireTime,
+		CreateTime: rtdb.CreateTime,
+	}
+
+	if rtdb.RevokeTime != nil {
+		rt.RevokeTime = *rtdb.RevokeTime
+	}
+
+	return rt
+}
+
+// emailVerificationTokenDB denotes an email verification
+// token data in the store.
+type emailVerificationTokenDB struct {
+	ID         int64      `db:"id"`
+	UserID     int64      `db:"user_id"`
+	Email      string     `db:"email"`
+	TokenHash  string     `db:"token_hash"`
+	ExpireTime time.Time  `db:"expire_time"`
+	UseTime    *time.Time `db:"use_time"`
+	CreateTime time.Time  `db:"create_time"`
+}
+
+// format formats database struct into domain struct.
+func (evtdb *emailVerificationTokenDB) format() user.EmailVerificationToken {
+	evt := user.EmailVerificationToken{
+		ID:         evtdb.ID,
+		UserID:     evtdb.UserID,
+		Email:      evtdb.Email,
+		TokenHash:  evtdb.TokenHash,
+		ExpireTime: evtdb.ExpireTime,
+		CreateTime: evtdb.CreateTime,
+	}
+
+	if evtdb.UseTime != nil {
+		evt.UseTime = *evtdb.UseTime
+	}
+
+	return evt
+}
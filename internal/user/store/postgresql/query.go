@@ -5,15 +5,19 @@ const queryCreateUser = `
 		user_info
 	(
 		email,
+		email_verified,
 		name,
 		password,
 		phone_number,
+		role,
 		create_time
 	) VALUES (
 		:email,
+		:email_verified,
 		:name,
 		:password,
 		:phone_number,
+		:role,
 		:create_time
 	) RETURNING
  		id
@@ -23,9 +27,11 @@ const queryGetUser = `
 	SELECT 
 		u.id,
 		u.email,
+		u.email_verified,
 		u.name,
 		u.password,
 		u.phone_number,
+		u.role,
 		u.create_time,
 		u.update_time
 	FROM
@@ -38,8 +44,175 @@ const queryUpdateUser = `
 	UPDATE
 		user_info
 	SET
+		email = :email,
+		email_verified = :email_verified,
+		name = :name,
 		password = :password,
+		phone_number = :phone_number,
 		update_time = :update_time
 	WHERE
 		id = :id
 `
+
+const queryRehashUserPassword = `
+	UPDATE
+		user_info
+	SET
+		password = :password,
+		update_time = :update_time
+	WHERE
+		id = :id AND
+		password = :current_password
+`
+
+const queryCreateRefreshToken = `
+	INSERT INTO
+		user_refresh_token
+	(
+		user_id,
+		family_id,
+		token_hash,
+		expire_time,
+		create_time
+	) VALUES (
+		:user_id,
+		:family_id,
+		:token_hash,
+		:expire_time,
+		:create_time
+	) RETURNING
+		id
+`
+
+const queryGetRefreshToken = `
+	SELECT
+		rt.id,
+		rt.user_id,
+		rt.family_id,
+		rt.token_hash,
+		rt.expire_time,
+		rt.revoke_time,
+		rt.create_time
+	FROM
+		user_refresh_token rt
+	WHERE
+		%s
+`
+
+const queryRevokeRefreshToken = `
+	UPDATE
+		user_refresh_token
+	SET
+		revoke_time = :revoke_time
+	WHERE
+		id = :id AND
+		revoke_time IS NULL
+`
+
+const queryRevokeRefreshTokenFamily = `
+	UPDATE
+		user_refresh_token
+	SET
+		revoke_time = :revoke_time
+	WHERE
+		family_id = :family_id AND
+		revoke_time IS NULL
+`
+
+const queryRevokeUserRefreshTokens = `
+	UPDATE
+		user_refresh_token
+	SET
+		revoke_time = :revoke_time
+	WHERE
+		user_id = :user_id AND
+		revoke_time IS NULL
+`
+
+const queryCreatePasswordResetToken = `
+	INSERT INTO
+		user_password_reset_token
+	(
+		user_id,
+		token_hash,
+		expire_time,
+		create_time
+	) VALUES (
+		:user_id,
+		:token_hash,
+		:expire_time,
+		:create_time
+	) RETURNING
+		id
+`
+
+const queryUsePasswordResetToken = `
+	UPDATE
+		user_password_reset_token
+	SET
+		use_time = :use_time
+	WHERE
+		token_hash = :token_hash AND
+		use_time IS NULL AND
+		expire_time > :use_time
+	RETURNING
+		user_id
+`
+
+const queryUpdateUserEmailVerified = `
+	UPDATE
+		user_info
+	SET
+		email_verified = :email_verified,
+		update_time = :update_time
+	WHERE
+		id = :id
+`
+
+const queryCreateEmailVerificationToken = `
+	INSERT INTO
+		user_email_verification_token
+	(
+		user_id,
+		email,
+		token_hash,
+		expire_time,
+		create_time
+	) VALUES (
+		:user_id,
+		:email,
+		:token_hash,
+		:expire_time,
+		:create_time
+	) RETURNING
+		id
+`
+
+const queryUseEmailVerificationToken = `
+	UPDATE
+		user_email_verification_token
+	SET
+		use_time = :use_time
+	WHERE
+		token_hash = :token_hash AND
+		use_time IS NULL AND
+		expire_time > :use_time
+	RETURNING
+		id,
+		user_id,
+		email,
+		token_hash,
+		expire_time,
+		use_time,
+		create_time
+`
+
+const queryRevokeEmailVerificationTokens = `
+	UPDATE
+		user_email_verification_token
+	SET
+		use_time = :use_time
+	WHERE
+		user_id = :user_id AND
+		use_time IS NULL
+`
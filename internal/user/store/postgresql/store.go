@@ -2,6 +2,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/synapsis-test/internal/user"
 )
@@ -24,6 +25,11 @@ type PGStoreClient interface {
 	// GetUserByID selects user with the given user ID.
 	GetUserByID(ctx context.Context, userID int64) (user.User, error)
 
+	// GetUserByIDForUpdate selects user with the given user
+	// ID and locks it until the transaction ends, so it is not
+	// updated in between. The client should use transaction.
+	GetUserByIDForUpdate(ctx context.Context, userID int64) (user.User, error)
+
 	// GetUserByEmail selects a user with the given
 	// email.
 	GetUserByEmail(ctx context.Context, email string) (user.User, error)
@@ -32,8 +38,84 @@ type PGStoreClient interface {
 	// for a user specified with the given user ID.
 	//
 	// UpdateUser do updates on all the fields except ID,
-	// Username, Type, CreateBy, and CreateTime. So, make
-	// sure to use current values in the given data if do
-	// not want to update some specific fields.
+	// Role, and CreateTime. So, make sure to use current
+	// values in the given data if do not want to update some
+	// specific fields.
+	//
+	// It returns user.ErrUserAlreadyExist if the email is
+	// already used by other user.
 	UpdateUser(ctx context.Context, user user.User) error
+
+	// RehashUserPassword replaces the password hash of the
+	// given user with the given one only if the stored hash is
+	// still the given current hash.
+	//
+	// It returns user.ErrDataNotFound if the password is
+	// changed in the meantime.
+	RehashUserPassword(ctx context.Context, user user.User, currentHash string) error
+
+	// CreateRefreshToken inserts the given refresh token.
+	//
+	// CreateRefreshToken returns created refresh token ID.
+	CreateRefreshToken(ctx context.Context, token user.RefreshToken) (int64, error)
+
+	// GetRefreshTokenByHash selects a refresh token with the
+	// given token hash.
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (user.RefreshToken, error)
+
+	// RevokeRefreshToken revokes the given refresh token at
+	// its revoke time.
+	//
+	// It returns user.ErrRevokedToken if the refresh token is
+	// already revoked.
+	RevokeRefreshToken(ctx context.Context, token user.RefreshToken) error
+
+	// RevokeRefreshTokenFamily revokes every refresh token
+	// that is not revoked yet in the given family at the given
+	// revoke time.
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokeTime time.Time) error
+
+	// RevokeUserRefreshTokens revokes every refresh token
+	// that is not revoked yet of the given user at the given
+	// revoke time.
+	RevokeUserRefreshTokens(ctx context.Context, userID int64, revokeTime time.Time) error
+
+	// CreatePasswordResetToken inserts the given password
+	// reset token.
+	//
+	// CreatePasswordResetToken returns created password reset
+	// token ID.
+	CreatePasswordResetToken(ctx context.Context, token user.PasswordResetToken) (int64, error)
+
+	// UsePasswordResetToken marks the password reset token
+	// with the given token hash as used at the given use time,
+	// and returns the user ID who requested it.
+	//
+	// It returns user.ErrInvalidToken if there is no such
+	// token that is neither used nor expired.
+	UsePasswordResetToken(ctx context.Context, tokenHash string, useTime time.Time) (int64, error)
+
+	// UpdateUserEmailVerified updates whether the email of the
+	// given user is verified.
+	UpdateUserEmailVerified(ctx context.Context, user user.User) error
+
+	// CreateEmailVerificationToken inserts the given email
+	// verification token.
+	//
+	// CreateEmailVerificationToken returns created email
+	// verification token ID.
+	CreateEmailVerificationToken(ctx context.Context, token user.EmailVerificationToken) (int64, error)
+
+	// UseEmailVerificationToken marks the email verification
+	// token with the given token hash as used at the given use
+	// time, and returns the used token.
+	//
+	// It returns user.ErrInvalidToken if there is no such
+	// token that is neither used nor expired.
+	UseEmailVerificationToken(ctx context.Context, tokenHash string, useTime time.Time) (user.EmailVerificationToken, error)
+
+	// RevokeEmailVerificationTokens marks every email
+	// verification token of the given user ID that is not
+	// used yet as used at the given use time.
+	RevokeEmailVerificationTokens(ctx context.Context, userID int64, useTime time.Time) error
 }
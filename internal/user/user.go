@@ -6,12 +6,51 @@ import (
 )
 
 type Service interface {
-	// CreateUser create new user as given.
+	// CreateUser create new user as given, and mails a token
+	// to verify the email of the user unless it is verified.
 	//
 	// CreateUser expects the given  user ID in the given
 	// user already assigned.
 	CreateUser(ctx context.Context, users User) (int64, error)
 
+	// GetUserByID returns the user with the given user ID.
+	// The password hash of the user is not returned.
+	GetUserByID(ctx context.Context, userID int64) (User, error)
+
+	// UpdateProfile updates name and phone number of the user
+	// with the user ID of the given user, and returns the
+	// updated user without the password hash. Empty fields in
+	// the given user keep their current values.
+	UpdateProfile(ctx context.Context, user User) (User, error)
+
+	// ChangeEmail mails a token to verify the new email of
+	// the user with the given user ID. The email is changed,
+	// and marked as verified, only once the token is used
+	// with VerifyEmail.
+	//
+	// It returns ErrUserAlreadyExist if the new email is used
+	// by other user, or ErrTooManyRequests if it is called for
+	// the same new email again too soon.
+	ChangeEmail(ctx context.Context, userID int64, newEmail string) error
+
+	// VerifyEmail marks the email of the user who is given
+	// the email verification token as verified. The token can
+	// only be used once, and only for the email it is sent to.
+	//
+	// If the token is sent by ChangeEmail, the email of the
+	// user is changed to the new email, and every other token
+	// of the user can not be used anymore.
+	VerifyEmail(ctx context.Context, token string) error
+
+	// ResendEmailVerification mails a new token to verify the
+	// email of the user with the given email. It does not
+	// return error if there is no user with unverified email,
+	// so the caller can not tell whether the email exists.
+	//
+	// It returns ErrTooManyRequests if it is called for the
+	// same email again too soon.
+	ResendEmailVerification(ctx context.Context, email string) error
+
 	// UpdatePassword, for the given user ID, updates user's
 	// password with the new password. Before updating, it
 	// checks whether the current password are correct.
@@ -22,35 +61,159 @@ type Service interface {
 	// current password.
 	ResetPassword(ctx context.Context, userID int64, newPassword string) error
 
+	// RequestPasswordReset mails a one-time token to reset
+	// the password of the user with the given email. It does
+	// not return error if there is no user with the email, so
+	// the caller can not tell whether the email exists.
+	RequestPasswordReset(ctx context.Context, email string) error
+
+	// ResetPasswordWithToken updates password of the user who
+	// requested the given password reset token with the new
+	// password. The token can only be used once, and every
+	// refresh token of the user is revoked.
+	ResetPasswordWithToken(ctx context.Context, token string, newPassword string) error
+
 	// LoginBasic checks the given email and password with
-	// the actual data. It returns a token and the encapsulated
-	// data if the login process is success.
-	LoginBasic(ctx context.Context, email string, password string) (string, TokenData, error)
+	// the actual data. It returns a new pair of access and
+	// refresh token and the data encapsulated in the access
+	// token if the login process is success.
+	//
+	// Failed logins are limited per email and per the given
+	// IP address the login comes from. It returns
+	// ErrTooManyRequests if the login is attempted again too
+	// soon, or ErrAccountLocked if the email has failed too
+	// many times.
+	//
+	// It returns ErrEmailNotVerified if verified email is
+	// required but the email of the user is not verified.
+	LoginBasic(ctx context.Context, email string, password string, ip string) (Token, TokenData, error)
 
-	// ValidateToken validates the given token and returns the
-	// data encapsulated in the token if the given token is
-	// valid.
+	// ValidateToken validates the given access token and
+	// returns the data encapsulated in the token if the given
+	// token is valid and not revoked.
 	ValidateToken(ctx context.Context, token string) (TokenData, error)
 
-	// RefreshToken validates the given token and returns a
-	// new token with the same encapsulated data but refreshed.
+	// RefreshToken exchanges the given refresh token with a
+	// new pair of access and refresh token. The given refresh
+	// token can only be used once.
+	//
+	// Using a refresh token that is already exchanged revokes
+	// every refresh token issued since the same login, and
+	// returns ErrRevokedToken.
+	RefreshToken(ctx context.Context, refreshToken string) (Token, error)
+
+	// Logout revokes the given access token, and the given
+	// refresh token together with every refresh token issued
+	// since the same login. Refresh token is optional.
+	Logout(ctx context.Context, accessToken string, refreshToken string) error
+
+	// EnsureAdmin makes sure the user with the email of the
+	// given user is an admin. The user is created as an admin
+	// if it does not exist yet. It returns the admin user ID.
 	//
-	// RefreshToken is used to avoid expired token.
-	RefreshToken(ctx context.Context, token string) (string, error)
+	// It returns ErrAdminEmailTaken if the email is used by a
+	// user who is not an admin, as anyone may have registered
+	// it, so it is never promoted.
+	EnsureAdmin(ctx context.Context, admin User) (int64, error)
 }
 
 type User struct {
-	ID          int64
-	Email       string
-	Name        string
-	Password    string
-	PhoneNumber string
-	CreateTime  time.Time
-	UpdateTime  time.Time
+	ID            int64
+	Email         string
+	EmailVerified bool
+	Name          string
+	Password      string
+	PhoneNumber   string
+	Role          Role
+	CreateTime    time.Time
+	UpdateTime    time.Time
 }
 
 // TokenData is the data that are encapsulated in a token.
 type TokenData struct {
-	UserID int64
-	Email  string
+	UserID        int64
+	Email         string
+	EmailVerified bool
+	Role          Role
+}
+
+// Token denotes a pair of tokens issued to a user.
+type Token struct {
+	AccessToken           string
+	AccessTokenExpireTime time.Time
+	RefreshToken          string
+}
+
+// EmailVerificationToken denotes a one-time token to verify
+// an email of a user. Only the hash of the token is stored.
+type EmailVerificationToken struct {
+	ID         int64
+	UserID     int64
+	Email      string // the email to verify
+	TokenHash  string
+	ExpireTime time.Time
+	UseTime    time.Time // zero if not used
+	CreateTime time.Time
+}
+
+// PasswordResetToken denotes a one-time token to reset
+// password of a user. Only the hash of the token is stored.
+type PasswordResetToken struct {
+	ID         int64
+	UserID     int64
+	TokenHash  string
+	ExpireTime time.Time
+	UseTime    time.Time // zero if not used
+	CreateTime time.Time
+}
+
+// RefreshToken denotes a refresh token issued to a user.
+// Only the hash of the token is stored.
+type RefreshToken struct {
+	ID         int64
+	UserID     int64
+	FamilyID   string // shared by every token rotated from the same login
+	TokenHash  string
+	ExpireTime time.Time
+	RevokeTime time.Time // zero if not revoked
+	CreateTime time.Time
+}
+
+// Role denotes role of a user.
+type Role int
+
+// Followings are the known role.
+const (
+	RoleUnknown  Role = 0
+	RoleCustomer Role = 1
+	RoleAdmin    Role = 2
+)
+
+var (
+	// roleName maps role to it's string representation.
+	roleName = map[Role]string{
+		RoleCustomer: "customer",
+		RoleAdmin:    "admin",
+	}
+)
+
+// Value returns int value of a role type.
+func (r Role) Value() int {
+	return int(r)
+}
+
+// String returns string representaion of a role type.
+func (r Role) String() string {
+	return roleName[r]
+}
+
+// ParseRole returns role for the given string
+// representation, or RoleUnknown if there is none.
+func ParseRole(name string) Role {
+	for role, roleStr := range roleName {
+		if roleStr == name {
+			return role
+		}
+	}
+	return RoleUnknown
 }
@@ -0,0 +1,27 @@
+-- orders created since hold their product and quantity only
+-- in their items, so the first item is put back in the order.
+-- The other items of an order of more than one product are
+-- lost.
+UPDATE transaction t
+SET
+	product_id = i.product_id,
+	quantity = i.quantity
+FROM (
+	SELECT DISTINCT ON (order_id)
+		order_id,
+		product_id,
+		quantity
+	FROM
+		order_item
+	ORDER BY
+		order_id,
+		id
+) i
+WHERE
+	i.order_id = t.id AND
+	(t.product_id IS NULL OR t.quantity IS NULL);
+
+ALTER TABLE transaction ALTER COLUMN product_id SET NOT NULL;
+ALTER TABLE transaction ALTER COLUMN quantity SET NOT NULL;
+
+DROP TABLE IF EXISTS order_item;
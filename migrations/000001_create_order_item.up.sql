@@ -0,0 +1,16 @@
+-- Orders hold their products as items, so the product and
+-- quantity of the order itself are no longer written.
+ALTER TABLE transaction ALTER COLUMN product_id DROP NOT NULL;
+ALTER TABLE transaction ALTER COLUMN quantity DROP NOT NULL;
+
+CREATE TABLE IF NOT EXISTS order_item (
+	id BIGSERIAL PRIMARY KEY,
+	order_id BIGINT NOT NULL REFERENCES transaction (id),
+	product_id BIGINT NOT NULL REFERENCES product (id),
+	price BIGINT NOT NULL,
+	quantity BIGINT NOT NULL CHECK (quantity > 0),
+	create_time TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_item_order_id_idx ON order_item (order_id);
+CREATE INDEX IF NOT EXISTS order_item_product_id_idx ON order_item (product_id);
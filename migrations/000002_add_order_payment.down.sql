@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS transaction_gateway_order_id_key;
+
+ALTER TABLE transaction DROP COLUMN IF EXISTS qr_string;
+ALTER TABLE transaction DROP COLUMN IF EXISTS payment_type;
+ALTER TABLE transaction DROP COLUMN IF EXISTS gateway_transaction_id;
+ALTER TABLE transaction DROP COLUMN IF EXISTS gateway_order_id;
@@ -0,0 +1,7 @@
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS gateway_order_id VARCHAR(50);
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS gateway_transaction_id VARCHAR(100);
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS payment_type VARCHAR(50);
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS qr_string TEXT;
+
+-- payment notifications are matched to orders by this ID
+CREATE UNIQUE INDEX IF NOT EXISTS transaction_gateway_order_id_key ON transaction (gateway_order_id);
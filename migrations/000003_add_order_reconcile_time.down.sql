@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS transaction_status_reconcile_time_idx;
+
+ALTER TABLE transaction DROP COLUMN IF EXISTS reconcile_time;
@@ -0,0 +1,4 @@
+-- pending orders are reconciled least recently checked first
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS reconcile_time TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS transaction_status_reconcile_time_idx ON transaction (status, reconcile_time NULLS FIRST, id);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS order_status_history (
+	id BIGSERIAL PRIMARY KEY,
+	order_id BIGINT NOT NULL REFERENCES transaction (id),
+	old_status INT NOT NULL,
+	new_status INT NOT NULL,
+	source INT NOT NULL,
+	create_time TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_status_history_order_id_idx ON order_status_history (order_id, create_time);
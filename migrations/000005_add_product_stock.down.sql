@@ -0,0 +1,4 @@
+ALTER TABLE product DROP CONSTRAINT IF EXISTS product_stock_check;
+
+ALTER TABLE product DROP COLUMN IF EXISTS reserved_stock;
+ALTER TABLE product DROP COLUMN IF EXISTS stock;
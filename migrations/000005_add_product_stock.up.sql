@@ -0,0 +1,10 @@
+-- stock is the quantity on hand, reserved_stock is the part
+-- of it held by orders waiting to be paid.
+--
+-- The stock on hand is not known here, so existing products
+-- start with no stock and can not be ordered until the
+-- operator sets it, see README.
+ALTER TABLE product ADD COLUMN IF NOT EXISTS stock BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE product ADD COLUMN IF NOT EXISTS reserved_stock BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE product ADD CONSTRAINT product_stock_check CHECK (reserved_stock >= 0 AND stock >= reserved_stock);
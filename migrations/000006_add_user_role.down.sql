@@ -0,0 +1 @@
+ALTER TABLE user_info DROP COLUMN IF EXISTS role;
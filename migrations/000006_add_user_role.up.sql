@@ -0,0 +1,2 @@
+-- role of the user, 1 for customer and 2 for admin
+ALTER TABLE user_info ADD COLUMN IF NOT EXISTS role INT NOT NULL DEFAULT 1;
@@ -0,0 +1,15 @@
+-- refresh tokens are stored hashed, and rotated within their
+-- family so a reused token revokes the whole family
+CREATE TABLE IF NOT EXISTS user_refresh_token (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES user_info (id),
+	family_id VARCHAR(64) NOT NULL,
+	token_hash VARCHAR(64) NOT NULL,
+	expire_time TIMESTAMPTZ NOT NULL,
+	revoke_time TIMESTAMPTZ,
+	create_time TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_refresh_token_token_hash_key ON user_refresh_token (token_hash);
+CREATE INDEX IF NOT EXISTS user_refresh_token_family_id_idx ON user_refresh_token (family_id);
+CREATE INDEX IF NOT EXISTS user_refresh_token_user_id_idx ON user_refresh_token (user_id);
@@ -0,0 +1,13 @@
+-- password reset tokens are stored hashed, and can only be
+-- used once before they expire
+CREATE TABLE IF NOT EXISTS user_password_reset_token (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES user_info (id),
+	token_hash VARCHAR(64) NOT NULL,
+	expire_time TIMESTAMPTZ NOT NULL,
+	use_time TIMESTAMPTZ,
+	create_time TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_password_reset_token_token_hash_key ON user_password_reset_token (token_hash);
+CREATE INDEX IF NOT EXISTS user_password_reset_token_user_id_idx ON user_password_reset_token (user_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_email_verification_token;
+
+ALTER TABLE user_info DROP COLUMN IF EXISTS email_verified;
@@ -0,0 +1,20 @@
+-- existing users signed up before emails are verified, so
+-- they are treated as verified and are not locked out of
+-- ordering, while new users start unverified
+ALTER TABLE user_info ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE user_info ALTER COLUMN email_verified SET DEFAULT false;
+
+-- email verification tokens are stored hashed, and verify the
+-- email they are sent to
+CREATE TABLE IF NOT EXISTS user_email_verification_token (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES user_info (id),
+	email VARCHAR(255) NOT NULL,
+	token_hash VARCHAR(64) NOT NULL,
+	expire_time TIMESTAMPTZ NOT NULL,
+	use_time TIMESTAMPTZ,
+	create_time TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_email_verification_token_token_hash_key ON user_email_verification_token (token_hash);
+CREATE INDEX IF NOT EXISTS user_email_verification_token_user_id_idx ON user_email_verification_token (user_id);
@@ -0,0 +1,3 @@
+-- deleted products are kept, so the orders of them are still
+-- intact
+ALTER TABLE product ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS category_name_lower_key;
+
+-- names are unique again across every category, so this fails
+-- if a deleted category shares its name with another one,
+-- which should be renamed first
+ALTER TABLE category ADD CONSTRAINT category_name_key UNIQUE (name);
+
+ALTER TABLE category DROP COLUMN IF EXISTS deleted_at;
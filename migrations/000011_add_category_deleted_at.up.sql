@@ -0,0 +1,9 @@
+-- deleted categories are kept, so the deleted products in
+-- them still have their category
+ALTER TABLE category ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- category names are unique regardless of case among the
+-- categories that are not deleted, so the name of a deleted
+-- category can be used again
+ALTER TABLE category DROP CONSTRAINT IF EXISTS category_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS category_name_lower_key ON category (LOWER(name)) WHERE deleted_at IS NULL;
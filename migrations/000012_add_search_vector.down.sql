@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS product_category_id_idx;
+DROP INDEX IF EXISTS product_search_vector_idx;
+
+ALTER TABLE category DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE product DROP COLUMN IF EXISTS search_vector;
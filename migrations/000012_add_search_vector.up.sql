@@ -0,0 +1,17 @@
+-- search vectors are generated once per write instead of per
+-- row on every search. Product name weights over
+-- its description, and category name is weighted below both.
+ALTER TABLE product ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+	setweight(to_tsvector('simple', name), 'A') ||
+	setweight(to_tsvector('simple', COALESCE(description, '')), 'B')
+) STORED;
+
+ALTER TABLE category ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+	setweight(to_tsvector('simple', name), 'C')
+) STORED;
+
+-- name suggestions only match the product search vector
+CREATE INDEX IF NOT EXISTS product_search_vector_idx ON product USING GIN (search_vector);
+
+-- products are joined with their category when searched
+CREATE INDEX IF NOT EXISTS product_category_id_idx ON product (category_id);